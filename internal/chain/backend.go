@@ -0,0 +1,100 @@
+package chain
+
+import (
+	"fmt"
+
+	"github.com/satonic/satonic-api/internal/config"
+)
+
+// ChainBackend provides access to the Bitcoin blockchain
+type ChainBackend interface {
+	// GetUTXOs retrieves the unspent outputs of an address
+	GetUTXOs(address string) ([]UTXO, error)
+
+	// GetTransaction retrieves a transaction by ID, or nil if it is unknown
+	GetTransaction(txid string) (*Transaction, error)
+
+	// GetOutspend retrieves the spending status of a transaction output
+	GetOutspend(txid string, vout uint32) (*Outspend, error)
+
+	// Broadcast broadcasts a raw hex encoded transaction and returns its ID
+	Broadcast(rawTxHex string) (string, error)
+
+	// GetFeeEstimates retrieves fee rate estimates keyed by confirmation target
+	GetFeeEstimates() (FeeEstimates, error)
+
+	// GetTipHeight retrieves the height of the best block
+	GetTipHeight() (int64, error)
+}
+
+// TxStatus represents the confirmation status of a transaction
+type TxStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight int64  `json:"block_height,omitempty"`
+	BlockHash   string `json:"block_hash,omitempty"`
+	BlockTime   int64  `json:"block_time,omitempty"`
+}
+
+// UTXO represents an unspent transaction output
+type UTXO struct {
+	TxID   string   `json:"txid"`
+	Vout   uint32   `json:"vout"`
+	Value  int64    `json:"value"` // in satoshis
+	Status TxStatus `json:"status"`
+}
+
+// TxInput represents an input of a transaction
+type TxInput struct {
+	TxID     string    `json:"txid"`
+	Vout     uint32    `json:"vout"`
+	Prevout  *TxOutput `json:"prevout,omitempty"`
+	Sequence uint32    `json:"sequence"`
+	Witness  []string  `json:"witness,omitempty"`
+}
+
+// TxOutput represents an output of a transaction
+type TxOutput struct {
+	ScriptPubKey string `json:"scriptpubkey"`
+	Address      string `json:"scriptpubkey_address,omitempty"`
+	Value        int64  `json:"value"` // in satoshis
+}
+
+// Transaction represents a Bitcoin transaction
+type Transaction struct {
+	TxID     string     `json:"txid"`
+	Version  int32      `json:"version"`
+	LockTime uint32     `json:"locktime"`
+	Vin      []TxInput  `json:"vin"`
+	Vout     []TxOutput `json:"vout"`
+	Weight   int64      `json:"weight"`
+	Fee      int64      `json:"fee"` // in satoshis
+	Status   TxStatus   `json:"status"`
+}
+
+// Outspend represents the spending status of a transaction output
+type Outspend struct {
+	Spent  bool     `json:"spent"`
+	TxID   string   `json:"txid,omitempty"` // spending transaction
+	Vin    uint32   `json:"vin,omitempty"`
+	Status TxStatus `json:"status"`
+}
+
+// FeeEstimates maps a confirmation target in blocks to a fee rate in sat/vB
+type FeeEstimates map[int]float64
+
+// New creates the chain backend selected in the configuration
+func New(cfg config.BitcoinConfig) (ChainBackend, error) {
+	params, err := cfg.ChainParams()
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.ChainBackend {
+	case "", "esplora":
+		return NewEsploraClient(cfg.EsploraURL), nil
+	case "fake":
+		return NewFakeBackend(params), nil
+	default:
+		return nil, fmt.Errorf("unknown chain backend %q", cfg.ChainBackend)
+	}
+}
@@ -0,0 +1,159 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EsploraClient is a ChainBackend backed by an Esplora or mempool.space
+// compatible HTTP API
+type EsploraClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewEsploraClient creates a new EsploraClient
+func NewEsploraClient(baseURL string) *EsploraClient {
+	return &EsploraClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// GetUTXOs retrieves the unspent outputs of an address
+func (c *EsploraClient) GetUTXOs(address string) ([]UTXO, error) {
+	utxos := []UTXO{}
+	if _, err := c.getJSON("/address/"+address+"/utxo", &utxos); err != nil {
+		return nil, err
+	}
+
+	return utxos, nil
+}
+
+// GetTransaction retrieves a transaction by ID, or nil if it is unknown
+func (c *EsploraClient) GetTransaction(txid string) (*Transaction, error) {
+	tx := &Transaction{}
+	found, err := c.getJSON("/tx/"+txid, tx)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// GetOutspend retrieves the spending status of a transaction output
+func (c *EsploraClient) GetOutspend(txid string, vout uint32) (*Outspend, error) {
+	outspend := &Outspend{}
+	found, err := c.getJSON("/tx/"+txid+"/outspend/"+strconv.FormatUint(uint64(vout), 10), outspend)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return &Outspend{}, nil
+	}
+
+	return outspend, nil
+}
+
+// Broadcast broadcasts a raw hex encoded transaction and returns its ID
+func (c *EsploraClient) Broadcast(rawTxHex string) (string, error) {
+	resp, err := c.httpClient.Post(c.baseURL+"/tx", "text/plain", strings.NewReader(rawTxHex))
+	if err != nil {
+		return "", fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to broadcast transaction: %s", strings.TrimSpace(string(body)))
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
+
+// GetFeeEstimates retrieves fee rate estimates keyed by confirmation target
+func (c *EsploraClient) GetFeeEstimates() (FeeEstimates, error) {
+	raw := map[string]float64{}
+	if _, err := c.getJSON("/fee-estimates", &raw); err != nil {
+		return nil, err
+	}
+
+	estimates := FeeEstimates{}
+	for target, rate := range raw {
+		blocks, err := strconv.Atoi(target)
+		if err != nil {
+			continue
+		}
+		estimates[blocks] = rate
+	}
+
+	return estimates, nil
+}
+
+// GetTipHeight retrieves the height of the best block
+func (c *EsploraClient) GetTipHeight() (int64, error) {
+	body, _, err := c.get("/blocks/tip/height")
+	if err != nil {
+		return 0, err
+	}
+
+	height, err := strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tip height: %w", err)
+	}
+
+	return height, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response. It returns
+// false if the resource was not found.
+func (c *EsploraClient) getJSON(path string, v interface{}) (bool, error) {
+	body, found, err := c.get(path)
+	if err != nil || !found {
+		return false, err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return false, fmt.Errorf("failed to decode response from %s: %w", path, err)
+	}
+
+	return true, nil
+}
+
+// get performs a GET request and returns the response body. It returns false
+// if the resource was not found.
+func (c *EsploraClient) get(path string) ([]byte, bool, error) {
+	resp, err := c.httpClient.Get(c.baseURL + path)
+	if err != nil {
+		return nil, false, fmt.Errorf("request to %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("request to %s failed with status %d: %s",
+			path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return body, true, nil
+}
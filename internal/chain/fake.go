@@ -0,0 +1,303 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// FakeBackend is an in-memory ChainBackend for tests and local development
+type FakeBackend struct {
+	mu           sync.Mutex
+	params       *chaincfg.Params
+	utxos        map[string][]UTXO
+	transactions map[string]*Transaction
+	spends       map[wire.OutPoint]Outspend
+	feeEstimates FeeEstimates
+	tipHeight    int64
+}
+
+// NewFakeBackend creates a new FakeBackend for a network
+func NewFakeBackend(params *chaincfg.Params) *FakeBackend {
+	return &FakeBackend{
+		params:       params,
+		utxos:        make(map[string][]UTXO),
+		transactions: make(map[string]*Transaction),
+		spends:       make(map[wire.OutPoint]Outspend),
+		feeEstimates: FeeEstimates{1: 10, 3: 5, 6: 2, 144: 1},
+		tipHeight:    1,
+	}
+}
+
+// AddUTXO adds an unspent output to an address
+func (f *FakeBackend) AddUTXO(address string, utxo UTXO) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.utxos[address] = append(f.utxos[address], utxo)
+}
+
+// AddTransaction adds a transaction
+func (f *FakeBackend) AddTransaction(tx *Transaction) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.transactions[tx.TxID] = tx
+}
+
+// SetFeeEstimates sets the fee rate estimates
+func (f *FakeBackend) SetFeeEstimates(estimates FeeEstimates) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.feeEstimates = estimates
+}
+
+// SetTipHeight sets the height of the best block
+func (f *FakeBackend) SetTipHeight(height int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.tipHeight = height
+}
+
+// ConfirmTransaction mines a transaction in a new block
+func (f *FakeBackend) ConfirmTransaction(txid string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tx, ok := f.transactions[txid]
+	if !ok {
+		return fmt.Errorf("transaction %s not found", txid)
+	}
+
+	f.tipHeight++
+	tx.Status = TxStatus{Confirmed: true, BlockHeight: f.tipHeight}
+
+	// Confirm the outputs created by the transaction
+	for address, utxos := range f.utxos {
+		for i := range utxos {
+			if utxos[i].TxID == txid {
+				f.utxos[address][i].Status = tx.Status
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetUTXOs retrieves the unspent outputs of an address
+func (f *FakeBackend) GetUTXOs(address string) ([]UTXO, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	utxos := make([]UTXO, len(f.utxos[address]))
+	copy(utxos, f.utxos[address])
+	return utxos, nil
+}
+
+// GetTransaction retrieves a transaction by ID, or nil if it is unknown
+func (f *FakeBackend) GetTransaction(txid string) (*Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tx, ok := f.transactions[txid]
+	if !ok {
+		return nil, nil
+	}
+
+	txCopy := *tx
+	return &txCopy, nil
+}
+
+// GetOutspend retrieves the spending status of a transaction output
+func (f *FakeBackend) GetOutspend(txid string, vout uint32) (*Outspend, error) {
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid txid: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	outspend, ok := f.spends[*wire.NewOutPoint(hash, vout)]
+	if !ok {
+		return &Outspend{}, nil
+	}
+
+	if tx, ok := f.transactions[outspend.TxID]; ok {
+		outspend.Status = tx.Status
+	}
+
+	return &outspend, nil
+}
+
+// Broadcast decodes a raw transaction, spends its inputs and credits its
+// outputs as unconfirmed UTXOs. An unconfirmed transaction spending the same
+// outputs is replaced.
+func (f *FakeBackend) Broadcast(rawTxHex string) (string, error) {
+	raw, err := hex.DecodeString(rawTxHex)
+	if err != nil {
+		return "", fmt.Errorf("invalid transaction hex: %w", err)
+	}
+
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	if err := msgTx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return "", fmt.Errorf("invalid transaction: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	txid := msgTx.TxHash().String()
+	tx := &Transaction{
+		TxID:     txid,
+		Version:  msgTx.Version,
+		LockTime: msgTx.LockTime,
+		Weight:   int64(msgTx.SerializeSizeStripped()*3 + msgTx.SerializeSize()),
+	}
+
+	// Replace unconfirmed transactions spending the same outputs
+	conflicts := map[string]bool{}
+	for _, txIn := range msgTx.TxIn {
+		previous, ok := f.spends[txIn.PreviousOutPoint]
+		if !ok || previous.TxID == txid {
+			continue
+		}
+		if conflict, ok := f.transactions[previous.TxID]; ok && conflict.Status.Confirmed {
+			return "", fmt.Errorf("input %s already spent", txIn.PreviousOutPoint)
+		}
+		conflicts[previous.TxID] = true
+	}
+	for conflictID := range conflicts {
+		f.evictTransaction(conflictID)
+	}
+
+	// Spend the inputs
+	for vin, txIn := range msgTx.TxIn {
+		f.spends[txIn.PreviousOutPoint] = Outspend{Spent: true, TxID: txid, Vin: uint32(vin)}
+
+		input := TxInput{
+			TxID:     txIn.PreviousOutPoint.Hash.String(),
+			Vout:     txIn.PreviousOutPoint.Index,
+			Sequence: txIn.Sequence,
+		}
+		for _, item := range txIn.Witness {
+			input.Witness = append(input.Witness, hex.EncodeToString(item))
+		}
+
+		for address, utxos := range f.utxos {
+			for i, utxo := range utxos {
+				if utxo.TxID == input.TxID && utxo.Vout == input.Vout {
+					input.Prevout = &TxOutput{Address: address, Value: utxo.Value}
+					f.utxos[address] = append(utxos[:i], utxos[i+1:]...)
+					break
+				}
+			}
+		}
+
+		tx.Vin = append(tx.Vin, input)
+	}
+
+	// Credit the outputs
+	for vout, txOut := range msgTx.TxOut {
+		output := TxOutput{
+			ScriptPubKey: hex.EncodeToString(txOut.PkScript),
+			Value:        txOut.Value,
+		}
+
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, f.params)
+		if err == nil && len(addrs) == 1 {
+			output.Address = addrs[0].EncodeAddress()
+			f.utxos[output.Address] = append(f.utxos[output.Address], UTXO{
+				TxID:  txid,
+				Vout:  uint32(vout),
+				Value: txOut.Value,
+			})
+		}
+
+		tx.Vout = append(tx.Vout, output)
+	}
+
+	// Compute the fee when every spent output is known
+	var totalIn, totalOut int64
+	for _, input := range tx.Vin {
+		if input.Prevout == nil {
+			totalIn = -1
+			break
+		}
+		totalIn += input.Prevout.Value
+	}
+	for _, output := range tx.Vout {
+		totalOut += output.Value
+	}
+	if totalIn >= 0 {
+		tx.Fee = totalIn - totalOut
+	}
+
+	f.transactions[txid] = tx
+	return txid, nil
+}
+
+// evictTransaction removes an unconfirmed transaction, its outputs and the
+// spends of its inputs
+func (f *FakeBackend) evictTransaction(txid string) {
+	tx, ok := f.transactions[txid]
+	if !ok {
+		return
+	}
+	delete(f.transactions, txid)
+
+	for address, utxos := range f.utxos {
+		kept := utxos[:0]
+		for _, utxo := range utxos {
+			if utxo.TxID != txid {
+				kept = append(kept, utxo)
+			}
+		}
+		f.utxos[address] = kept
+	}
+
+	for _, input := range tx.Vin {
+		hash, err := chainhash.NewHashFromStr(input.TxID)
+		if err != nil {
+			continue
+		}
+		delete(f.spends, *wire.NewOutPoint(hash, input.Vout))
+
+		if input.Prevout != nil {
+			f.utxos[input.Prevout.Address] = append(f.utxos[input.Prevout.Address], UTXO{
+				TxID:  input.TxID,
+				Vout:  input.Vout,
+				Value: input.Prevout.Value,
+			})
+		}
+	}
+}
+
+// GetFeeEstimates retrieves fee rate estimates keyed by confirmation target
+func (f *FakeBackend) GetFeeEstimates() (FeeEstimates, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	estimates := FeeEstimates{}
+	for target, rate := range f.feeEstimates {
+		estimates[target] = rate
+	}
+
+	return estimates, nil
+}
+
+// GetTipHeight retrieves the height of the best block
+func (f *FakeBackend) GetTipHeight() (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.tipHeight, nil
+}
@@ -8,14 +8,22 @@ import (
 	"path/filepath"
 
 	"encoding/base64"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/satonic/satonic-api/internal/models"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Database DatabaseConfig `json:"database"`
-	Email    EmailConfig    `json:"email"`
-	Auth     AuthConfig     `json:"auth"`
+	Server    ServerConfig    `json:"server"`
+	Database  DatabaseConfig  `json:"database"`
+	Email     EmailConfig     `json:"email"`
+	Auth      AuthConfig      `json:"auth"`
+	Bitcoin   BitcoinConfig   `json:"bitcoin"`
+	Ord       OrdConfig       `json:"ord"`
+	Scheduler SchedulerConfig `json:"scheduler"`
+	Auction   AuctionConfig   `json:"auction"`
+	Fees      FeeConfig       `json:"fees"`
 }
 
 // ServerConfig contains server related configurations
@@ -44,10 +52,101 @@ type EmailConfig struct {
 
 // AuthConfig contains authentication related configurations
 type AuthConfig struct {
-	JWTSecret     string `json:"jwt_secret"`
-	JWTExpiration int    `json:"jwt_expiration"` // in hours
-	CodeLength    int    `json:"code_length"`
-	CodeExpiration int   `json:"code_expiration"` // in minutes
+	JWTSecret           string `json:"jwt_secret"`
+	JWTExpiration       int    `json:"jwt_expiration"` // in hours
+	CodeLength          int    `json:"code_length"`
+	CodeExpiration      int    `json:"code_expiration"` // in minutes
+	Domain              string `json:"domain"`
+	ChallengeExpiration int    `json:"challenge_expiration"` // in minutes
+}
+
+// BitcoinConfig contains Bitcoin network related configurations
+type BitcoinConfig struct {
+	Network                 string `json:"network"`       // mainnet, testnet, signet or regtest
+	ChainBackend            string `json:"chain_backend"` // esplora or fake
+	EsploraURL              string `json:"esplora_url"`
+	SettlementConfirmations int    `json:"settlement_confirmations"`
+	SettlementPollInterval  int    `json:"settlement_poll_interval"` // in seconds
+}
+
+// ChainParams returns the chain parameters for the configured network
+func (c BitcoinConfig) ChainParams() (*chaincfg.Params, error) {
+	switch c.Network {
+	case "", "mainnet":
+		return &chaincfg.MainNetParams, nil
+	case "testnet":
+		return &chaincfg.TestNet3Params, nil
+	case "signet":
+		return &chaincfg.SigNetParams, nil
+	case "regtest":
+		return &chaincfg.RegressionNetParams, nil
+	default:
+		return nil, fmt.Errorf("unknown bitcoin network %q", c.Network)
+	}
+}
+
+// OrdConfig contains ord indexer related configurations
+type OrdConfig struct {
+	Backend      string `json:"backend"` // ord or fake
+	URL          string `json:"url"`
+	FixturesFile string `json:"fixtures_file"` // used by the fake backend
+}
+
+// SchedulerConfig contains background auction scheduler configurations
+type SchedulerConfig struct {
+	TickInterval int   `json:"tick_interval"` // in seconds
+	LockKey      int64 `json:"lock_key"`      // Postgres advisory lock used for leader election
+}
+
+// AuctionConfig contains platform-wide auction rules
+type AuctionConfig struct {
+	BidIncrement models.BidIncrement `json:"bid_increment"` // auctions may override it
+	// Percent of the high bid a seller owes for cancelling an auction that has
+	// bids. It is recorded on the auction but not collected. Sellers cannot
+	// cancel such auctions when it is not set.
+	CancellationPenalty *float64 `json:"cancellation_penalty,omitempty"`
+	// Seconds the winner of an auction or the buyer of a listing has to
+	// broadcast the settlement after it ends. The sale is then cancelled and
+	// the NFT released. Zero lets winners take as long as they want.
+	SettlementTimeout int `json:"settlement_timeout"`
+}
+
+// FeeConfig contains the platform fee and creator royalties taken from the
+// price of every sale
+type FeeConfig struct {
+	PlatformAddress     string                   `json:"platform_address"`
+	PlatformBasisPoints int                      `json:"platform_basis_points"`
+	Royalties           map[string]RoyaltyConfig `json:"royalties"` // by collection
+}
+
+// RoyaltyConfig contains the creator royalty of a collection
+type RoyaltyConfig struct {
+	Address     string `json:"address"`
+	BasisPoints int    `json:"basis_points"`
+}
+
+// Validate checks that every fee has a recipient and that no sale pays out
+// its whole price in fees
+func (f FeeConfig) Validate() error {
+	if f.PlatformBasisPoints < 0 || (f.PlatformBasisPoints > 0 && f.PlatformAddress == "") {
+		return fmt.Errorf("platform fee needs an address and must not be negative")
+	}
+
+	for collection, royalty := range f.Royalties {
+		if royalty.BasisPoints < 0 || (royalty.BasisPoints > 0 && royalty.Address == "") {
+			return fmt.Errorf("royalty of collection %s needs an address and must not be negative", collection)
+		}
+
+		if f.PlatformBasisPoints+royalty.BasisPoints >= 10000 {
+			return fmt.Errorf("fees of collection %s must be below 10000 basis points", collection)
+		}
+	}
+
+	if f.PlatformBasisPoints >= 10000 {
+		return fmt.Errorf("platform fee must be below 10000 basis points")
+	}
+
+	return nil
 }
 
 // Load loads the configuration from file and environment
@@ -68,9 +167,33 @@ func Load() (*Config, error) {
 			FromEmail: "noreply@satonic.com",
 		},
 		Auth: AuthConfig{
-			JWTExpiration: 24,
-			CodeLength:    6,
-			CodeExpiration: 15,
+			JWTExpiration:       24,
+			CodeLength:          6,
+			CodeExpiration:      15,
+			Domain:              "satonic.com",
+			ChallengeExpiration: 5,
+		},
+		Bitcoin: BitcoinConfig{
+			Network:                 "mainnet",
+			ChainBackend:            "esplora",
+			EsploraURL:              "https://mempool.space/api",
+			SettlementConfirmations: 1,
+			SettlementPollInterval:  30,
+		},
+		Ord: OrdConfig{
+			Backend: "ord",
+			URL:     "https://ordinals.com",
+		},
+		Scheduler: SchedulerConfig{
+			TickInterval: 5,
+			LockKey:      7368617,
+		},
+		Auction: AuctionConfig{
+			BidIncrement: models.BidIncrement{
+				Type:   models.BidIncrementFixed,
+				Amount: 1000,
+			},
+			SettlementTimeout: 24 * 60 * 60,
 		},
 	}
 
@@ -140,6 +263,49 @@ func Load() (*Config, error) {
 		cfg.Email.FromEmail = fromEmail
 	}
 
+	if authDomain := os.Getenv("AUTH_DOMAIN"); authDomain != "" {
+		cfg.Auth.Domain = authDomain
+	}
+
+	if network := os.Getenv("BITCOIN_NETWORK"); network != "" {
+		cfg.Bitcoin.Network = network
+	}
+	if chainBackend := os.Getenv("CHAIN_BACKEND"); chainBackend != "" {
+		cfg.Bitcoin.ChainBackend = chainBackend
+	}
+	if esploraURL := os.Getenv("ESPLORA_URL"); esploraURL != "" {
+		cfg.Bitcoin.EsploraURL = esploraURL
+	}
+	if _, err := cfg.Bitcoin.ChainParams(); err != nil {
+		return nil, err
+	}
+
+	if ordBackend := os.Getenv("ORD_BACKEND"); ordBackend != "" {
+		cfg.Ord.Backend = ordBackend
+	}
+	if ordURL := os.Getenv("ORD_URL"); ordURL != "" {
+		cfg.Ord.URL = ordURL
+	}
+
+	if tickInterval := os.Getenv("SCHEDULER_TICK_INTERVAL"); tickInterval != "" {
+		var interval int
+		if _, err := fmt.Sscanf(tickInterval, "%d", &interval); err == nil {
+			cfg.Scheduler.TickInterval = interval
+		}
+	}
+
+	if err := cfg.Auction.BidIncrement.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Fees.Validate(); err != nil {
+		return nil, err
+	}
+
+	if penalty := cfg.Auction.CancellationPenalty; penalty != nil && (*penalty < 0 || *penalty > 100) {
+		return nil, fmt.Errorf("cancellation penalty must be between 0 and 100 percent")
+	}
+
 	if jwtSecret := os.Getenv("JWT_SECRET"); jwtSecret != "" {
 		cfg.Auth.JWTSecret = jwtSecret
 	} else if cfg.Auth.JWTSecret == "" {
@@ -152,4 +318,4 @@ func Load() (*Config, error) {
 	}
 
 	return cfg, nil
-} 
\ No newline at end of file
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -73,6 +74,15 @@ func CreateAuction(auctionService *services.AuctionService) http.HandlerFunc {
 		// Create auction
 		auction, err := auctionService.Create(req, userID)
 		if err != nil {
+			// Return PSBT validation failures as structured errors
+			var psbtErr *services.PSBTValidationError
+			if errors.As(err, &psbtErr) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(psbtErr)
+				return
+			}
+
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -84,6 +94,68 @@ func CreateAuction(auctionService *services.AuctionService) http.HandlerFunc {
 	}
 }
 
+// UpdateAuction handles editing a draft auction
+func UpdateAuction(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get auction ID from URL
+		auctionID := chi.URLParam(r, "id")
+		if auctionID == "" {
+			http.Error(w, "Auction ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Parse request body
+		var req models.UpdateAuctionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Set auction ID from URL
+		req.AuctionID = auctionID
+
+		// Update auction
+		auction, err := auctionService.UpdateAuction(req, userID)
+		if err != nil {
+			writeListingError(w, err)
+			return
+		}
+
+		// Return auction
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(auction)
+	}
+}
+
+// CancelAuction handles a seller cancelling their auction
+func CancelAuction(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get auction ID from URL
+		auctionID := chi.URLParam(r, "id")
+		if auctionID == "" {
+			http.Error(w, "Auction ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Cancel auction
+		auction, err := auctionService.CancelAuction(auctionID, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Return auction
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(auction)
+	}
+}
+
 // FinalizeAuction handles finalizing an auction
 func FinalizeAuction(auctionService *services.AuctionService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -120,6 +192,145 @@ func FinalizeAuction(auctionService *services.AuctionService) http.HandlerFunc {
 	}
 }
 
+// PrepareSettlement handles building the settlement PSBT for the winning bidder
+func PrepareSettlement(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get auction ID from URL
+		auctionID := chi.URLParam(r, "id")
+		if auctionID == "" {
+			http.Error(w, "Auction ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Parse request body
+		var req models.SettlementRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Set auction ID from URL
+		req.AuctionID = auctionID
+
+		// Build settlement PSBT
+		settlement, err := auctionService.PrepareSettlement(req, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Return settlement PSBT
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(settlement)
+	}
+}
+
+// GetBidHistory handles exporting the signed bid history of an auction
+func GetBidHistory(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get auction ID from URL
+		auctionID := chi.URLParam(r, "id")
+		if auctionID == "" {
+			http.Error(w, "Auction ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Get the bid history
+		history, err := auctionService.GetBidHistory(auctionID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if history == nil {
+			http.Error(w, "Auction not found", http.StatusNotFound)
+			return
+		}
+
+		// Return the bid history
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(history)
+	}
+}
+
+// GetAuctionEvents handles retrieving the event log of an auction
+func GetAuctionEvents(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get auction ID from URL
+		auctionID := chi.URLParam(r, "id")
+		if auctionID == "" {
+			http.Error(w, "Auction ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Only return the events after a sequence number, if given
+		after := 0
+		if afterStr := r.URL.Query().Get("after"); afterStr != "" {
+			var err error
+			after, err = strconv.Atoi(afterStr)
+			if err != nil || after < 0 {
+				http.Error(w, "Invalid after parameter", http.StatusBadRequest)
+				return
+			}
+		}
+
+		// Get the events
+		events, err := auctionService.GetEvents(auctionID, after)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if events == nil {
+			http.Error(w, "Auction not found", http.StatusNotFound)
+			return
+		}
+
+		// Return the events
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(events)
+	}
+}
+
+// BuyNow handles buying an auction at its buy-now price
+func BuyNow(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get auction ID from URL
+		auctionID := chi.URLParam(r, "id")
+		if auctionID == "" {
+			http.Error(w, "Auction ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Parse request body
+		var req models.BuyNowRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Set auction ID from URL
+		req.AuctionID = auctionID
+
+		// Buy the auction
+		response, err := auctionService.BuyNow(req, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Return the purchase and its settlement PSBT
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
 // Helper function to parse auction query parameters
 func parseAuctionParams(r *http.Request) models.AuctionParams {
 	params := models.AuctionParams{}
@@ -133,6 +344,12 @@ func parseAuctionParams(r *http.Request) models.AuctionParams {
 	// Get seller filter
 	params.SellerID = r.URL.Query().Get("seller_id")
 
+	// Get type filter
+	typeStr := r.URL.Query().Get("type")
+	if typeStr != "" {
+		params.Type = models.AuctionType(typeStr)
+	}
+
 	// Get bidder filter
 	params.BidderID = r.URL.Query().Get("bidder_id")
 
@@ -9,6 +9,29 @@ import (
 	"github.com/satonic/satonic-api/internal/services"
 )
 
+// WalletChallenge handles issuing a login challenge for a wallet
+func WalletChallenge(authService *services.AuthService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.ChallengeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Issue challenge
+		challenge, err := authService.CreateChallenge(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Return challenge
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(challenge)
+	}
+}
+
 // WalletLogin handles wallet authentication
 func WalletLogin(authService *services.AuthService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
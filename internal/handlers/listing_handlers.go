@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/satonic/satonic-api/internal/models"
+	"github.com/satonic/satonic-api/internal/services"
+)
+
+// GetListings handles retrieving fixed-price listings
+func GetListings(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse query parameters
+		params := parseAuctionParams(r)
+		params.Type = models.AuctionTypeFixedPrice
+
+		// Get listings
+		response, err := auctionService.List(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Return listings
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// CreateListing handles listing an NFT at a fixed price
+func CreateListing(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Parse request body
+		var req models.CreateListingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Create listing
+		listing, err := auctionService.CreateListing(req, userID)
+		if err != nil {
+			writeListingError(w, err)
+			return
+		}
+
+		// Return listing
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(listing)
+	}
+}
+
+// RepriceListing handles changing the price of a listing
+func RepriceListing(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get listing ID from URL
+		listingID := chi.URLParam(r, "id")
+		if listingID == "" {
+			http.Error(w, "Listing ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Parse request body
+		var req models.RepriceListingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Set listing ID from URL
+		req.ListingID = listingID
+
+		// Reprice listing
+		listing, err := auctionService.RepriceListing(req, userID)
+		if err != nil {
+			writeListingError(w, err)
+			return
+		}
+
+		// Return listing
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listing)
+	}
+}
+
+// DeleteListing handles delisting an NFT
+func DeleteListing(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get listing ID from URL
+		listingID := chi.URLParam(r, "id")
+		if listingID == "" {
+			http.Error(w, "Listing ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Delist
+		if err := auctionService.Delist(listingID, userID); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// PurchaseListing handles buying a listing at its price
+func PurchaseListing(auctionService *services.AuctionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get listing ID from URL
+		listingID := chi.URLParam(r, "id")
+		if listingID == "" {
+			http.Error(w, "Listing ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Parse request body
+		var req models.BuyNowRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Set listing ID from URL
+		req.AuctionID = listingID
+
+		// Buy the listing
+		response, err := auctionService.BuyNow(req, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Return the purchase and its settlement PSBT
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// writeListingError returns PSBT validation failures as structured errors
+func writeListingError(w http.ResponseWriter, err error) {
+	var psbtErr *services.PSBTValidationError
+	if errors.As(err, &psbtErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(psbtErr)
+		return
+	}
+
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
@@ -60,6 +60,29 @@ func GetNFT(nftService *services.NFTService) http.HandlerFunc {
 	}
 }
 
+// GetCollectionStats handles retrieving the market summary of a collection
+func GetCollectionStats(nftService *services.NFTService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get collection from URL
+		collection := chi.URLParam(r, "collection")
+		if collection == "" {
+			http.Error(w, "Collection is required", http.StatusBadRequest)
+			return
+		}
+
+		// Get collection stats
+		stats, err := nftService.GetCollectionStats(collection)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Return collection stats
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+	}
+}
+
 // Helper function to parse NFT query parameters
 func parseNFTParams(r *http.Request) models.NFTParams {
 	params := models.NFTParams{}
@@ -0,0 +1,434 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/satonic/satonic-api/internal/models"
+	"github.com/satonic/satonic-api/internal/services"
+)
+
+// GetOffers handles retrieving offers
+func GetOffers(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse query parameters
+		params := parseOfferParams(r)
+
+		// Get offers
+		response, err := offerService.List(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Return offers
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// GetOffer handles retrieving a single offer
+func GetOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get offer ID from URL
+		offerID := chi.URLParam(r, "id")
+		if offerID == "" {
+			http.Error(w, "Offer ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Get offer
+		offer, err := offerService.GetByID(offerID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if offer == nil {
+			http.Error(w, "Offer not found", http.StatusNotFound)
+			return
+		}
+
+		// Return offer
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(offer)
+	}
+}
+
+// CreateOffer handles making an offer on an NFT
+func CreateOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Parse request body
+		var req models.CreateOfferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Create offer
+		response, err := offerService.CreateOffer(req, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Return the offer and its PSBT
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// SignOffer handles funding an offer with its signed PSBT
+func SignOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get offer ID from URL
+		offerID := chi.URLParam(r, "id")
+		if offerID == "" {
+			http.Error(w, "Offer ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Parse request body
+		var req models.SignOfferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Set offer ID from URL
+		req.OfferID = offerID
+
+		// Sign offer
+		offer, err := offerService.SignOffer(req, userID)
+		if err != nil {
+			writeListingError(w, err)
+			return
+		}
+
+		// Return offer
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(offer)
+	}
+}
+
+// AcceptOffer handles accepting an offer, returning the PSBT for the owner to sign
+func AcceptOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get offer ID from URL
+		offerID := chi.URLParam(r, "id")
+		if offerID == "" {
+			http.Error(w, "Offer ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Accept offer
+		settlement, err := offerService.AcceptOffer(offerID, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Return settlement PSBT
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(settlement)
+	}
+}
+
+// FinalizeOffer handles settling an accepted offer with the owner's signed PSBT
+func FinalizeOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get offer ID from URL
+		offerID := chi.URLParam(r, "id")
+		if offerID == "" {
+			http.Error(w, "Offer ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Parse request body
+		var req models.FinalizeOfferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Set offer ID from URL
+		req.OfferID = offerID
+
+		// Finalize offer
+		offer, err := offerService.FinalizeOffer(req, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Return offer
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(offer)
+	}
+}
+
+// RejectOffer handles rejecting an offer
+func RejectOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get offer ID from URL
+		offerID := chi.URLParam(r, "id")
+		if offerID == "" {
+			http.Error(w, "Offer ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Reject offer
+		if err := offerService.RejectOffer(offerID, userID); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// CancelOffer handles withdrawing an offer
+func CancelOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get offer ID from URL
+		offerID := chi.URLParam(r, "id")
+		if offerID == "" {
+			http.Error(w, "Offer ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Cancel offer
+		if err := offerService.CancelOffer(offerID, userID); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetCollectionOffers handles retrieving collection offers
+func GetCollectionOffers(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse query parameters
+		params := parseCollectionOfferParams(r)
+
+		// Get collection offers
+		response, err := offerService.ListCollectionOffers(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Return collection offers
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// GetCollectionOffer handles retrieving a single collection offer
+func GetCollectionOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get collection offer ID from URL
+		collectionOfferID := chi.URLParam(r, "id")
+		if collectionOfferID == "" {
+			http.Error(w, "Collection offer ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Get collection offer
+		collectionOffer, err := offerService.GetCollectionOffer(collectionOfferID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if collectionOffer == nil {
+			http.Error(w, "Collection offer not found", http.StatusNotFound)
+			return
+		}
+
+		// Return collection offer
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(collectionOffer)
+	}
+}
+
+// CreateCollectionOffer handles making an offer on any NFTs of a collection
+func CreateCollectionOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Parse request body
+		var req models.CreateCollectionOfferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Create collection offer
+		collectionOffer, err := offerService.CreateCollectionOffer(req, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Return collection offer
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(collectionOffer)
+	}
+}
+
+// FillCollectionOffer handles selling an NFT into a collection offer
+func FillCollectionOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get collection offer ID from URL
+		collectionOfferID := chi.URLParam(r, "id")
+		if collectionOfferID == "" {
+			http.Error(w, "Collection offer ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Parse request body
+		var req models.FillCollectionOfferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Set collection offer ID from URL
+		req.CollectionOfferID = collectionOfferID
+
+		// Fill collection offer
+		offer, err := offerService.FillCollectionOffer(req, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Return the offer created for the NFT
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(offer)
+	}
+}
+
+// CancelCollectionOffer handles withdrawing a collection offer
+func CancelCollectionOffer(offerService *services.OfferService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get user ID from context
+		userID := r.Context().Value(UserIDKey).(string)
+
+		// Get collection offer ID from URL
+		collectionOfferID := chi.URLParam(r, "id")
+		if collectionOfferID == "" {
+			http.Error(w, "Collection offer ID is required", http.StatusBadRequest)
+			return
+		}
+
+		// Cancel collection offer
+		if err := offerService.CancelCollectionOffer(collectionOfferID, userID); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Helper function to parse offer query parameters
+func parseOfferParams(r *http.Request) models.OfferParams {
+	params := models.OfferParams{}
+
+	// Get NFT filter
+	params.NFTID = r.URL.Query().Get("nft_id")
+
+	// Get status filter
+	statusStr := r.URL.Query().Get("status")
+	if statusStr != "" {
+		params.Status = models.OfferStatus(statusStr)
+	}
+
+	// Get offerer and owner filters
+	params.OffererID = r.URL.Query().Get("offerer_id")
+	params.OwnerID = r.URL.Query().Get("owner_id")
+
+	// Get pagination
+	pageStr := r.URL.Query().Get("page")
+	if pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err == nil && page > 0 {
+			params.Page = page
+		}
+	}
+
+	pageSizeStr := r.URL.Query().Get("page_size")
+	if pageSizeStr != "" {
+		pageSize, err := strconv.Atoi(pageSizeStr)
+		if err == nil && pageSize > 0 {
+			params.PageSize = pageSize
+		}
+	}
+
+	return params
+}
+
+// Helper function to parse collection offer query parameters
+func parseCollectionOfferParams(r *http.Request) models.CollectionOfferParams {
+	params := models.CollectionOfferParams{}
+
+	// Get collection and offerer filters
+	params.Collection = r.URL.Query().Get("collection")
+	params.OffererID = r.URL.Query().Get("offerer_id")
+
+	// Get status filter
+	statusStr := r.URL.Query().Get("status")
+	if statusStr != "" {
+		params.Status = models.CollectionOfferStatus(statusStr)
+	}
+
+	// Get pagination
+	pageStr := r.URL.Query().Get("page")
+	if pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err == nil && page > 0 {
+			params.Page = page
+		}
+	}
+
+	pageSizeStr := r.URL.Query().Get("page_size")
+	if pageSizeStr != "" {
+		pageSize, err := strconv.Atoi(pageSizeStr)
+		if err == nil && pageSize > 0 {
+			params.PageSize = pageSize
+		}
+	}
+
+	return params
+}
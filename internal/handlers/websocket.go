@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -45,6 +46,32 @@ type BidMessage struct {
 	AuctionID string `json:"auction_id"`
 	WalletID  string `json:"wallet_id"`
 	Amount    int64  `json:"amount"`
+	MaxAmount *int64 `json:"max_amount,omitempty"`
+	// Receives the inscription of a Dutch auction the bid buys
+	ReceiveAddress string `json:"receive_address,omitempty"`
+	models.BidSignature
+}
+
+// BuyNowMessage represents a buy-now purchase sent over WebSocket
+type BuyNowMessage struct {
+	AuctionID      string `json:"auction_id"`
+	WalletID       string `json:"wallet_id"`
+	ReceiveAddress string `json:"receive_address,omitempty"`
+	models.BidSignature
+}
+
+// CommitBidMessage represents a sealed bid commitment sent over WebSocket
+type CommitBidMessage struct {
+	AuctionID  string `json:"auction_id"`
+	WalletID   string `json:"wallet_id"`
+	Commitment string `json:"commitment"`
+}
+
+// RevealBidMessage represents a sealed bid reveal sent over WebSocket
+type RevealBidMessage struct {
+	AuctionID string `json:"auction_id"`
+	Amount    int64  `json:"amount"`
+	Salt      string `json:"salt"`
 }
 
 // Client represents a WebSocket client connection
@@ -64,6 +91,10 @@ type Hub struct {
 	// Clients by auction ID that they're watching
 	auctionClients map[string]map[*Client]bool
 
+	// Guards clients and auctionClients, which services update from their own
+	// goroutines through NotifyAuction
+	mu sync.Mutex
+
 	// Inbound messages from the clients
 	broadcast chan []byte
 
@@ -77,9 +108,9 @@ type Hub struct {
 	auctionService *services.AuctionService
 }
 
-// NewHub creates a new hub
+// NewHub creates a new hub and subscribes it to auction status transitions
 func NewHub(auctionService *services.AuctionService) *Hub {
-	return &Hub{
+	hub := &Hub{
 		broadcast:      make(chan []byte),
 		register:       make(chan *Client),
 		unregister:     make(chan *Client),
@@ -87,6 +118,9 @@ func NewHub(auctionService *services.AuctionService) *Hub {
 		auctionClients: make(map[string]map[*Client]bool),
 		auctionService: auctionService,
 	}
+	auctionService.SetNotifier(hub)
+
+	return hub
 }
 
 // Run starts the hub
@@ -94,28 +128,74 @@ func (h *Hub) Run() {
 	for {
 		select {
 		case client := <-h.register:
+			h.mu.Lock()
 			h.clients[client] = true
+			h.mu.Unlock()
 		case client := <-h.unregister:
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-			}
+			h.mu.Lock()
+			h.removeClient(client)
+			h.mu.Unlock()
 		case message := <-h.broadcast:
 			// Broadcast message to all clients
+			h.mu.Lock()
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
+				h.queue(client, message)
 			}
+			h.mu.Unlock()
 		}
 	}
 }
 
+// removeClient drops a client and all of its auction subscriptions and closes
+// its send channel. The caller must hold h.mu.
+func (h *Hub) removeClient(client *Client) {
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+
+	delete(h.clients, client)
+	close(client.send)
+
+	for auctionID, clients := range h.auctionClients {
+		delete(clients, client)
+		if len(clients) == 0 {
+			delete(h.auctionClients, auctionID)
+		}
+	}
+}
+
+// queue queues a message for a registered client, dropping the client if its
+// buffer is full. The caller must hold h.mu.
+func (h *Hub) queue(client *Client, message []byte) {
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+
+	select {
+	case client.send <- message:
+	default:
+		h.removeClient(client)
+	}
+}
+
+// sendToClient queues a message for a single client
+func (h *Hub) sendToClient(client *Client, message []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.queue(client, message)
+}
+
 // RegisterAuctionClient registers a client to receive updates for a specific auction
 func (h *Hub) RegisterAuctionClient(client *Client, auctionID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Clients that were dropped must not be resubscribed
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+
 	if _, ok := h.auctionClients[auctionID]; !ok {
 		h.auctionClients[auctionID] = make(map[*Client]bool)
 	}
@@ -124,6 +204,9 @@ func (h *Hub) RegisterAuctionClient(client *Client, auctionID string) {
 
 // UnregisterAuctionClient unregisters a client from receiving updates for a specific auction
 func (h *Hub) UnregisterAuctionClient(client *Client, auctionID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	if _, ok := h.auctionClients[auctionID]; ok {
 		delete(h.auctionClients[auctionID], client)
 		if len(h.auctionClients[auctionID]) == 0 {
@@ -134,17 +217,49 @@ func (h *Hub) UnregisterAuctionClient(client *Client, auctionID string) {
 
 // BroadcastToAuction broadcasts a message to all clients subscribed to an auction
 func (h *Hub) BroadcastToAuction(auctionID string, message []byte) {
-	if clients, ok := h.auctionClients[auctionID]; ok {
-		for client := range clients {
-			select {
-			case client.send <- message:
-			default:
-				close(client.send)
-				delete(h.clients, client)
-				delete(clients, client)
-			}
-		}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.auctionClients[auctionID] {
+		h.queue(client, message)
+	}
+}
+
+// NotifyAuction broadcasts a typed message to all clients subscribed to an auction
+func (h *Hub) NotifyAuction(auctionID, messageType string, payload interface{}) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("error marshalling %s payload: %v", messageType, err)
+		return
 	}
+
+	message := WebSocketMessage{
+		Type:    messageType,
+		Payload: payloadBytes,
+	}
+	messageBytes, _ := json.Marshal(message)
+	h.BroadcastToAuction(auctionID, messageBytes)
+}
+
+// sendMessage sends a typed message to the client only
+func (c *Client) sendMessage(messageType string, payload interface{}) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("error marshalling %s payload: %v", messageType, err)
+		return
+	}
+
+	message := WebSocketMessage{
+		Type:    messageType,
+		Payload: payloadBytes,
+	}
+	messageBytes, _ := json.Marshal(message)
+	c.hub.sendToClient(c, messageBytes)
+}
+
+// sendError sends an error message to the client
+func (c *Client) sendError(message string) {
+	c.sendMessage("error", map[string]string{"message": message})
 }
 
 // readPump pumps messages from the WebSocket connection to the hub
@@ -212,25 +327,28 @@ func (c *Client) readPump() {
 					Payload: json.RawMessage(`{"message":"Not authenticated"}`),
 				}
 				responseBytes, _ := json.Marshal(response)
-				c.send <- responseBytes
+				c.hub.sendToClient(c, responseBytes)
 				continue
 			}
 
 			// Place the bid
 			bidRequest := models.PlaceBidRequest{
-				AuctionID: bidMessage.AuctionID,
-				Amount:    bidMessage.Amount,
-				WalletID:  bidMessage.WalletID,
+				AuctionID:      bidMessage.AuctionID,
+				Amount:         bidMessage.Amount,
+				WalletID:       bidMessage.WalletID,
+				MaxAmount:      bidMessage.MaxAmount,
+				ReceiveAddress: bidMessage.ReceiveAddress,
+				BidSignature:   bidMessage.BidSignature,
 			}
 
-			bid, err := c.hub.auctionService.PlaceBid(bidRequest, c.userID)
+			bid, settlement, err := c.hub.auctionService.PlaceBid(bidRequest, c.userID)
 			if err != nil {
 				response := WebSocketMessage{
 					Type:    "error",
 					Payload: json.RawMessage(`{"message":"` + err.Error() + `"}`),
 				}
 				responseBytes, _ := json.Marshal(response)
-				c.send <- responseBytes
+				c.hub.sendToClient(c, responseBytes)
 				continue
 			}
 
@@ -267,7 +385,91 @@ func (c *Client) readPump() {
 				Payload: bidBytes,
 			}
 			bidResponseBytes, _ := json.Marshal(bidResponse)
-			c.send <- bidResponseBytes
+			c.hub.sendToClient(c, bidResponseBytes)
+
+			// Send the buyer of a Dutch auction their settlement PSBT
+			if settlement != nil {
+				c.sendMessage("settlement_prepared", settlement)
+			}
+
+		case "buy_now":
+			// Buy an auction at its buy-now price
+			var buyNowMessage BuyNowMessage
+			if err := json.Unmarshal(wsMessage.Payload, &buyNowMessage); err != nil {
+				log.Printf("error parsing buy_now payload: %v", err)
+				continue
+			}
+
+			if c.userID == "" {
+				c.sendError("Not authenticated")
+				continue
+			}
+
+			purchase, err := c.hub.auctionService.BuyNow(models.BuyNowRequest{
+				AuctionID:      buyNowMessage.AuctionID,
+				WalletID:       buyNowMessage.WalletID,
+				ReceiveAddress: buyNowMessage.ReceiveAddress,
+				BidSignature:   buyNowMessage.BidSignature,
+			}, c.userID)
+			if err != nil {
+				c.sendError(err.Error())
+				continue
+			}
+
+			// Send the buyer their settlement PSBT
+			c.sendMessage("buy_now_completed", purchase)
+
+		case "commit_bid":
+			// Commit a sealed bid
+			var commitMessage CommitBidMessage
+			if err := json.Unmarshal(wsMessage.Payload, &commitMessage); err != nil {
+				log.Printf("error parsing commit_bid payload: %v", err)
+				continue
+			}
+
+			if c.userID == "" {
+				c.sendError("Not authenticated")
+				continue
+			}
+
+			commitment, err := c.hub.auctionService.CommitBid(models.CommitBidRequest{
+				AuctionID:  commitMessage.AuctionID,
+				WalletID:   commitMessage.WalletID,
+				Commitment: commitMessage.Commitment,
+			}, c.userID)
+			if err != nil {
+				c.sendError(err.Error())
+				continue
+			}
+
+			// Only the bidder hears about their commitment
+			c.sendMessage("bid_committed", commitment)
+
+		case "reveal_bid":
+			// Reveal a sealed bid
+			var revealMessage RevealBidMessage
+			if err := json.Unmarshal(wsMessage.Payload, &revealMessage); err != nil {
+				log.Printf("error parsing reveal_bid payload: %v", err)
+				continue
+			}
+
+			if c.userID == "" {
+				c.sendError("Not authenticated")
+				continue
+			}
+
+			commitment, err := c.hub.auctionService.RevealBid(models.RevealBidRequest{
+				AuctionID: revealMessage.AuctionID,
+				Amount:    revealMessage.Amount,
+				Salt:      revealMessage.Salt,
+			}, c.userID)
+			if err != nil {
+				c.sendError(err.Error())
+				continue
+			}
+
+			// Amounts stay private until the reveal phase closes
+			c.sendMessage("bid_revealed", commitment)
 		}
 	}
 }
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestHubDropsClientsOnce(t *testing.T) {
+	hub := &Hub{
+		broadcast:      make(chan []byte),
+		register:       make(chan *Client),
+		unregister:     make(chan *Client),
+		clients:        make(map[*Client]bool),
+		auctionClients: make(map[string]map[*Client]bool),
+	}
+	go hub.Run()
+
+	// Clients with a one message buffer are dropped by the second broadcast
+	var clients []*Client
+	for i := 0; i < 20; i++ {
+		client := &Client{hub: hub, send: make(chan []byte, 1)}
+		hub.register <- client
+		hub.RegisterAuctionClient(client, "a1")
+		hub.RegisterAuctionClient(client, fmt.Sprintf("b%d", i))
+		clients = append(clients, client)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			hub.NotifyAuction("a1", "auction_status", map[string]string{"status": "ended"})
+		}()
+		go func() {
+			defer wg.Done()
+			hub.broadcast <- []byte("{}")
+		}()
+	}
+	for _, client := range clients {
+		wg.Add(1)
+		go func(client *Client) {
+			defer wg.Done()
+			hub.unregister <- client
+			client.sendError("closed")
+		}(client)
+	}
+	wg.Wait()
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if len(hub.clients) != 0 {
+		t.Errorf("got %d clients left, want 0", len(hub.clients))
+	}
+	if len(hub.auctionClients) != 0 {
+		t.Errorf("got subscriptions to %d auctions left, want 0", len(hub.auctionClients))
+	}
+	for _, client := range clients {
+		// Drain the buffer, then the channel must be closed
+		for range client.send {
+		}
+	}
+}
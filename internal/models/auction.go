@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -12,49 +15,262 @@ const (
 	AuctionStatusActive    AuctionStatus = "active"
 	AuctionStatusCompleted AuctionStatus = "completed"
 	AuctionStatusCancelled AuctionStatus = "cancelled"
+	// Bidding closed with a winning bid, waiting for the winner to settle
+	AuctionStatusEnded AuctionStatus = "ended"
+	// Settlement transaction broadcast, waiting for confirmations
+	AuctionStatusSettling AuctionStatus = "settling"
+	// Settlement transaction confirmed and the NFT transferred
+	AuctionStatusSettled AuctionStatus = "settled"
+	// Settlement transaction dropped or replaced, the winner may settle again
+	AuctionStatusSettlementFailed AuctionStatus = "settlement_failed"
+	// Sealed-bid auction closed to commitments, bidders reveal their amounts
+	AuctionStatusRevealing AuctionStatus = "revealing"
 )
 
+// AuctionType represents how the price of an auction is discovered
+type AuctionType string
+
+const (
+	// Ascending price, the highest bid at the end time wins
+	AuctionTypeEnglish AuctionType = "english"
+	// Descending price, the first purchase wins
+	AuctionTypeDutch AuctionType = "dutch"
+	// Committed sealed bids, revealed after the end time
+	AuctionTypeSealed AuctionType = "sealed"
+	// Fixed-price listing, bought outright at the start price
+	AuctionTypeFixedPrice AuctionType = "fixed_price"
+)
+
+// ListingNoExpiry is the end time of fixed-price listings that stay up until
+// they are bought or delisted
+var ListingNoExpiry = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// PriceSchedule represents how the price of a Dutch auction decays
+type PriceSchedule string
+
+const (
+	// The price falls continuously from the start price to the floor
+	PriceScheduleLinear PriceSchedule = "linear"
+	// The price falls in equal steps every PriceStepInterval seconds
+	PriceScheduleStepped PriceSchedule = "stepped"
+)
+
+// SealedPricing represents what the winner of a sealed-bid auction pays
+type SealedPricing string
+
+const (
+	// The winner pays their own bid
+	SealedPricingFirstPrice SealedPricing = "first_price"
+	// The winner pays the second highest bid (Vickrey)
+	SealedPricingSecondPrice SealedPricing = "second_price"
+)
+
+// CommitmentStatus represents the status of a sealed bid commitment
+type CommitmentStatus string
+
+const (
+	CommitmentStatusCommitted CommitmentStatus = "committed"
+	CommitmentStatusRevealed  CommitmentStatus = "revealed"
+	// Not revealed before the reveal phase closed
+	CommitmentStatusForfeited CommitmentStatus = "forfeited"
+)
+
+// AwaitingSettlement reports whether the winner of an auction in this status
+// may settle it
+func (s AuctionStatus) AwaitingSettlement() bool {
+	return s == AuctionStatusActive || s == AuctionStatusEnded || s == AuctionStatusSettlementFailed
+}
+
 // Auction represents an NFT auction in the system
 type Auction struct {
-	ID            string        `json:"id" db:"id"`
-	NFTID         string        `json:"nft_id" db:"nft_id"`
-	SellerWalletID string       `json:"seller_wallet_id" db:"seller_wallet_id"`
-	StartPrice    int64         `json:"start_price" db:"start_price"` // in satoshis
-	ReservePrice  *int64        `json:"reserve_price,omitempty" db:"reserve_price"`
-	BuyNowPrice   *int64        `json:"buy_now_price,omitempty" db:"buy_now_price"`
-	CurrentBid    *int64        `json:"current_bid,omitempty" db:"current_bid"`
-	CurrentBidderID *string     `json:"current_bidder_id,omitempty" db:"current_bidder_id"`
-	StartTime     time.Time     `json:"start_time" db:"start_time"`
-	EndTime       time.Time     `json:"end_time" db:"end_time"`
-	Status        AuctionStatus `json:"status" db:"status"`
-	PSBT          string        `json:"psbt" db:"psbt"` // Partially Signed Bitcoin Transaction
-	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time     `json:"updated_at" db:"updated_at"`
-	NFT           *NFT          `json:"nft,omitempty"`
-	Bids          []Bid         `json:"bids,omitempty"`
+	ID                 string        `json:"id" db:"id"`
+	Type               AuctionType   `json:"type" db:"type"`
+	NFTID              string        `json:"nft_id" db:"nft_id"`
+	SellerWalletID     string        `json:"seller_wallet_id" db:"seller_wallet_id"`
+	StartPrice         int64         `json:"start_price" db:"start_price"` // in satoshis
+	ReservePrice       *int64        `json:"reserve_price,omitempty" db:"reserve_price"`
+	BuyNowPrice        *int64        `json:"buy_now_price,omitempty" db:"buy_now_price"`
+	CurrentBid         *int64        `json:"current_bid,omitempty" db:"current_bid"`
+	CurrentBidderID    *string       `json:"current_bidder_id,omitempty" db:"current_bidder_id"`
+	StartTime          time.Time     `json:"start_time" db:"start_time"`
+	EndTime            time.Time     `json:"end_time" db:"end_time"`
+	Status             AuctionStatus `json:"status" db:"status"`
+	PSBT               string        `json:"-" db:"psbt"` // signed listing, only spent by settlements
+	SettlementPSBT     *string       `json:"-" db:"settlement_psbt"`
+	SettlementTxID     *string       `json:"settlement_txid,omitempty" db:"settlement_txid"`
+	SettlementWalletID *string       `json:"settlement_wallet_id,omitempty" db:"settlement_wallet_id"`
+	ExtensionWindow    int           `json:"extension_window" db:"extension_window"` // in seconds, 0 disables soft close
+	ExtensionLength    int           `json:"extension_length" db:"extension_length"` // in seconds
+	MaxExtensions      *int          `json:"max_extensions,omitempty" db:"max_extensions"`
+	ExtensionCount     int           `json:"extension_count" db:"extension_count"`
+	BidIncrement       *BidIncrement `json:"bid_increment,omitempty" db:"bid_increment"` // overrides the platform schedule
+	FloorPrice         *int64        `json:"floor_price,omitempty" db:"floor_price"`     // Dutch auctions, in satoshis
+	PriceSchedule      PriceSchedule `json:"price_schedule,omitempty" db:"price_schedule"`
+	PriceStepInterval  int           `json:"price_step_interval,omitempty" db:"price_step_interval"` // in seconds
+	CurrentPrice       *int64        `json:"current_price,omitempty" db:"-"`                         // Dutch auctions, in satoshis
+	RevealEndTime      *time.Time    `json:"reveal_end_time,omitempty" db:"reveal_end_time"`         // sealed-bid auctions
+	SealedPricing      SealedPricing `json:"sealed_pricing,omitempty" db:"sealed_pricing"`
+	CancelPenalty      *int64        `json:"cancellation_penalty,omitempty" db:"cancellation_penalty"`
+	Fees               *FeeBreakdown `json:"fees,omitempty" db:"-"`
+	MinimumBid         int64         `json:"minimum_bid" db:"-"`     // next acceptable bid, in satoshis
+	ProxyMaxBid        *int64        `json:"-" db:"proxy_max_bid"`   // hidden maximum of the current bidder
+	ProxyWalletID      *string       `json:"-" db:"proxy_wallet_id"` // wallet automatic bids are placed from
+	CreatedAt          time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at" db:"updated_at"`
+	NFT                *NFT          `json:"nft,omitempty"`
+	Lot                []NFT         `json:"lot,omitempty"` // every NFT of a lot auction, starting with NFT
+	Bids               []Bid         `json:"bids,omitempty"`
+}
+
+// BidIncrementType represents how the minimum raise over the current bid is computed
+type BidIncrementType string
+
+const (
+	// A fixed number of satoshis
+	BidIncrementFixed BidIncrementType = "fixed"
+	// A percentage of the current bid
+	BidIncrementPercent BidIncrementType = "percent"
+	// A fixed or percentage increment chosen by the price band of the current bid
+	BidIncrementTiered BidIncrementType = "tiered"
+)
+
+// BidIncrement represents a minimum bid increment schedule
+type BidIncrement struct {
+	Type    BidIncrementType   `json:"type"`
+	Amount  int64              `json:"amount,omitempty"`  // in satoshis, for fixed increments
+	Percent float64            `json:"percent,omitempty"` // for percent increments
+	Tiers   []BidIncrementTier `json:"tiers,omitempty"`   // for tiered increments, ordered by From
+}
+
+// BidIncrementTier represents the increment applied from a price band upwards
+type BidIncrementTier struct {
+	From    int64   `json:"from"`              // in satoshis
+	Amount  int64   `json:"amount,omitempty"`  // in satoshis
+	Percent float64 `json:"percent,omitempty"` // used when Amount is not set
+}
+
+// Validate checks that the schedule is complete and its tiers are ordered
+func (b BidIncrement) Validate() error {
+	switch b.Type {
+	case BidIncrementFixed:
+		if b.Amount <= 0 {
+			return fmt.Errorf("fixed bid increment must be positive")
+		}
+	case BidIncrementPercent:
+		if b.Percent <= 0 {
+			return fmt.Errorf("percent bid increment must be positive")
+		}
+	case BidIncrementTiered:
+		if len(b.Tiers) == 0 {
+			return fmt.Errorf("tiered bid increment needs at least one tier")
+		}
+		for i, tier := range b.Tiers {
+			if tier.From < 0 || (i > 0 && tier.From <= b.Tiers[i-1].From) {
+				return fmt.Errorf("bid increment tiers must be in ascending price order")
+			}
+			if (tier.Amount > 0) == (tier.Percent > 0) {
+				return fmt.Errorf("bid increment tier must set either amount or percent")
+			}
+			if tier.Amount < 0 || tier.Percent < 0 {
+				return fmt.Errorf("bid increment tier must be positive")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown bid increment type %q", b.Type)
+	}
+
+	return nil
+}
+
+// Value implements driver.Valuer, storing the schedule as JSON
+func (b BidIncrement) Value() (driver.Value, error) {
+	return json.Marshal(b)
+}
+
+// Scan implements sql.Scanner, reading the schedule from JSON
+func (b *BidIncrement) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, b)
+	case string:
+		return json.Unmarshal([]byte(v), b)
+	default:
+		return fmt.Errorf("cannot scan %T into BidIncrement", src)
+	}
 }
 
 // Bid represents a bid on an auction
 type Bid struct {
-	ID         string    `json:"id" db:"id"`
-	AuctionID  string    `json:"auction_id" db:"auction_id"`
-	BidderID   string    `json:"bidder_id" db:"bidder_id"`
-	WalletID   string    `json:"wallet_id" db:"wallet_id"`
-	Amount     int64     `json:"amount" db:"amount"` // in satoshis
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	Accepted   bool      `json:"accepted" db:"accepted"`
-	Signature  *string   `json:"signature,omitempty" db:"signature"`
+	ID        string    `json:"id" db:"id"`
+	AuctionID string    `json:"auction_id" db:"auction_id"`
+	BidderID  string    `json:"bidder_id" db:"bidder_id"`
+	WalletID  string    `json:"wallet_id" db:"wallet_id"`
+	Amount    int64     `json:"amount" db:"amount"` // in satoshis
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Accepted  bool      `json:"accepted" db:"accepted"`
+	Signature *string   `json:"signature,omitempty" db:"signature"`
+	Message   *string   `json:"-" db:"message"`           // signed by the wallet, see the bid history
+	MaxAmount *int64    `json:"-" db:"max_amount"`        // hidden proxy maximum, in satoshis
+	Automatic bool      `json:"automatic" db:"automatic"` // placed by the bidder's proxy
+}
+
+// BidCommitment represents a sealed bid. The commitment is the hex encoded
+// SHA-256 of "<amount>:<salt>"; the amount is only known once revealed and is
+// never sent to clients.
+type BidCommitment struct {
+	ID         string           `json:"id" db:"id"`
+	AuctionID  string           `json:"auction_id" db:"auction_id"`
+	BidderID   string           `json:"bidder_id" db:"bidder_id"`
+	WalletID   string           `json:"wallet_id" db:"wallet_id"`
+	Commitment string           `json:"commitment" db:"commitment"`
+	Amount     *int64           `json:"-" db:"amount"` // in satoshis
+	Status     CommitmentStatus `json:"status" db:"status"`
+	CreatedAt  time.Time        `json:"created_at" db:"created_at"`
+	RevealedAt *time.Time       `json:"revealed_at,omitempty" db:"revealed_at"`
 }
 
 // CreateAuctionRequest represents a request to create an auction
 type CreateAuctionRequest struct {
-	NFTID        string    `json:"nft_id"`
-	StartPrice   int64     `json:"start_price"`
-	ReservePrice *int64    `json:"reserve_price,omitempty"`
-	BuyNowPrice  *int64    `json:"buy_now_price,omitempty"`
-	StartTime    time.Time `json:"start_time"`
-	EndTime      time.Time `json:"end_time"`
-	PSBT         string    `json:"psbt"`
+	Type         AuctionType `json:"type,omitempty"` // defaults to english
+	NFTID        string      `json:"nft_id"`
+	NFTIDs       []string    `json:"nft_ids,omitempty"` // lot auctions list every NFT here instead
+	StartPrice   int64       `json:"start_price"`
+	ReservePrice *int64      `json:"reserve_price,omitempty"`
+	BuyNowPrice  *int64      `json:"buy_now_price,omitempty"`
+	StartTime    time.Time   `json:"start_time"`
+	EndTime      time.Time   `json:"end_time"`
+	PSBT         string      `json:"psbt"`
+	// Soft close: a bid in the last ExtensionWindow seconds pushes the end
+	// time to ExtensionLength seconds after the bid
+	ExtensionWindow int  `json:"extension_window,omitempty"` // in seconds
+	ExtensionLength int  `json:"extension_length,omitempty"` // in seconds
+	MaxExtensions   *int `json:"max_extensions,omitempty"`
+	// Overrides the platform bid increment schedule
+	BidIncrement *BidIncrement `json:"bid_increment,omitempty"`
+	// Dutch auctions fall from StartPrice to FloorPrice between the start and
+	// end time. The listing PSBT must pay the seller the floor price.
+	FloorPrice        *int64        `json:"floor_price,omitempty"`
+	PriceSchedule     PriceSchedule `json:"price_schedule,omitempty"`      // defaults to linear
+	PriceStepInterval int           `json:"price_step_interval,omitempty"` // in seconds, for stepped schedules
+	// Sealed-bid auctions take commitments until EndTime and reveals until
+	// RevealEndTime
+	RevealEndTime *time.Time    `json:"reveal_end_time,omitempty"`
+	SealedPricing SealedPricing `json:"sealed_pricing,omitempty"` // defaults to first_price
+}
+
+// UpdateAuctionRequest represents a request to edit the prices and times of a
+// draft auction. Settings that are left out keep their current value.
+type UpdateAuctionRequest struct {
+	AuctionID     string     `json:"auction_id"`
+	StartPrice    *int64     `json:"start_price,omitempty"`
+	ReservePrice  *int64     `json:"reserve_price,omitempty"`
+	BuyNowPrice   *int64     `json:"buy_now_price,omitempty"`
+	FloorPrice    *int64     `json:"floor_price,omitempty"`
+	StartTime     *time.Time `json:"start_time,omitempty"`
+	EndTime       *time.Time `json:"end_time,omitempty"`
+	RevealEndTime *time.Time `json:"reveal_end_time,omitempty"`
+	// New listing PSBT, required when the price paid to the seller changes
+	PSBT string `json:"psbt,omitempty"`
 }
 
 // PlaceBidRequest represents a request to place a bid on an auction
@@ -62,12 +278,111 @@ type PlaceBidRequest struct {
 	AuctionID string `json:"auction_id"`
 	Amount    int64  `json:"amount"`
 	WalletID  string `json:"wallet_id"`
+	// Hidden maximum the engine bids up to on the bidder's behalf
+	MaxAmount *int64 `json:"max_amount,omitempty"`
+	// Receives the inscription of a Dutch auction the bid buys
+	ReceiveAddress string `json:"receive_address,omitempty"`
+	BidSignature
+}
+
+// BidSignature is the bidding wallet's signature over the canonical message
+// of a bid, built from the auction, amount, wallet address, nonce and
+// timestamp
+type BidSignature struct {
+	Signature string    `json:"signature"`
+	Nonce     string    `json:"nonce"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CommitBidRequest represents a request to commit a sealed bid
+type CommitBidRequest struct {
+	AuctionID  string `json:"auction_id"`
+	WalletID   string `json:"wallet_id"`
+	Commitment string `json:"commitment"`
+}
+
+// RevealBidRequest represents a request to reveal a sealed bid
+type RevealBidRequest struct {
+	AuctionID string `json:"auction_id"`
+	Amount    int64  `json:"amount"`
+	Salt      string `json:"salt"`
+}
+
+// CreateListingRequest represents a request to list an NFT at a fixed price
+type CreateListingRequest struct {
+	NFTID     string     `json:"nft_id"`
+	Price     int64      `json:"price"` // in satoshis
+	PSBT      string     `json:"psbt"`  // must pay the seller the price
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// RepriceListingRequest represents a request to change the price of a listing
+type RepriceListingRequest struct {
+	ListingID string `json:"listing_id"`
+	Price     int64  `json:"price"` // in satoshis
+	PSBT      string `json:"psbt"`  // new listing PSBT paying the seller the price
+}
+
+// BuyNowRequest represents a request to buy an auction at its buy-now price
+type BuyNowRequest struct {
+	AuctionID      string `json:"auction_id"`
+	WalletID       string `json:"wallet_id"`
+	ReceiveAddress string `json:"receive_address,omitempty"` // defaults to the buying wallet
+	BidSignature
+}
+
+// BuyNowResponse represents a completed buy-now purchase and the settlement
+// PSBT prepared for the buyer
+type BuyNowResponse struct {
+	Bid        *Bid            `json:"bid"`
+	Settlement *SettlementPSBT `json:"settlement,omitempty"` // missing if it could not be prepared yet
+}
+
+// SettlementRequest represents a request to prepare the settlement PSBT of an auction
+type SettlementRequest struct {
+	AuctionID      string `json:"auction_id"`
+	ReceiveAddress string `json:"receive_address,omitempty"` // defaults to the winning bid's wallet
 }
 
 // FinalizeAuctionRequest represents a request to finalize an auction
 type FinalizeAuctionRequest struct {
-	AuctionID  string `json:"auction_id"`
-	Signature  string `json:"signature"`
+	AuctionID string `json:"auction_id"`
+	PSBT      string `json:"psbt"` // settlement PSBT signed by the winner
+}
+
+// FeeBreakdown splits the price of a sale between the platform, the creators
+// of the NFTs sold and the seller. Amounts are in satoshis.
+type FeeBreakdown struct {
+	Price          int64     `json:"price"`
+	PlatformFee    int64     `json:"platform_fee"`
+	Royalties      []Royalty `json:"royalties,omitempty"`
+	SellerProceeds int64     `json:"seller_proceeds"`
+}
+
+// Royalty represents the royalty paid to the creator of a collection
+type Royalty struct {
+	Collection string `json:"collection"`
+	Address    string `json:"address"`
+	Amount     int64  `json:"amount"` // in satoshis
+}
+
+// SignedBid represents a bid in the exported history of an auction
+type SignedBid struct {
+	ID            string    `json:"id" db:"id"`
+	BidderID      string    `json:"bidder_id" db:"bidder_id"`
+	WalletAddress string    `json:"wallet_address" db:"wallet_address"`
+	Amount        int64     `json:"amount" db:"amount"` // in satoshis
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	Automatic     bool      `json:"automatic" db:"automatic"`
+	Message       *string   `json:"message,omitempty" db:"message"`
+	Signature     *string   `json:"signature,omitempty" db:"signature"`
+	MaxAmount     *int64    `json:"-" db:"max_amount"`
+}
+
+// BidHistoryResponse represents the exported bid history of an auction
+type BidHistoryResponse struct {
+	AuctionID string      `json:"auction_id"`
+	Bids      []SignedBid `json:"bids"`
 }
 
 // AuctionListResponse represents the response for listing auctions
@@ -80,9 +395,33 @@ type AuctionListResponse struct {
 
 // AuctionParams represents the parameters for filtering auctions
 type AuctionParams struct {
-	Status     AuctionStatus `json:"status"`
-	SellerID   string        `json:"seller_id"`
-	BidderID   string        `json:"bidder_id"`
-	Page       int           `json:"page"`
-	PageSize   int           `json:"page_size"`
-} 
\ No newline at end of file
+	Status   AuctionStatus `json:"status"`
+	Type     AuctionType   `json:"type"`
+	SellerID string        `json:"seller_id"`
+	BidderID string        `json:"bidder_id"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+// AuctionStatusUpdate represents an auction status transition pushed to subscribers
+type AuctionStatusUpdate struct {
+	AuctionID      string        `json:"auction_id"`
+	Status         AuctionStatus `json:"status"`
+	SettlementTxID *string       `json:"settlement_txid,omitempty"`
+	Confirmations  int64         `json:"confirmations,omitempty"`
+	Reason         string        `json:"reason,omitempty"`
+	CancelPenalty  *int64        `json:"cancellation_penalty,omitempty"`
+}
+
+// AuctionPriceUpdate represents a Dutch auction price step pushed to subscribers
+type AuctionPriceUpdate struct {
+	AuctionID    string `json:"auction_id"`
+	CurrentPrice int64  `json:"current_price"`
+}
+
+// AuctionExtension represents a soft close extension pushed to subscribers
+type AuctionExtension struct {
+	AuctionID      string    `json:"auction_id"`
+	EndTime        time.Time `json:"end_time"`
+	ExtensionCount int       `json:"extension_count"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuctionEventType represents what happened to an auction
+type AuctionEventType string
+
+const (
+	AuctionEventCreated            AuctionEventType = "created"
+	AuctionEventUpdated            AuctionEventType = "updated"
+	AuctionEventRepriced           AuctionEventType = "repriced"
+	AuctionEventActivated          AuctionEventType = "activated"
+	AuctionEventBidPlaced          AuctionEventType = "bid_placed"
+	AuctionEventExtended           AuctionEventType = "extended"
+	AuctionEventRevealing          AuctionEventType = "revealing"
+	AuctionEventBidCommitted       AuctionEventType = "bid_committed"
+	AuctionEventBidRevealed        AuctionEventType = "bid_revealed"
+	AuctionEventEnded              AuctionEventType = "ended"
+	AuctionEventCancelled          AuctionEventType = "cancelled"
+	AuctionEventSettlementPrepared AuctionEventType = "settlement_prepared"
+	AuctionEventSettlementStarted  AuctionEventType = "settlement_started"
+	AuctionEventSettled            AuctionEventType = "settled"
+	AuctionEventSettlementFailed   AuctionEventType = "settlement_failed"
+	AuctionEventStatusChanged      AuctionEventType = "status_changed"
+)
+
+// StatusEventType returns the type of the event recording a move to a status
+func StatusEventType(status AuctionStatus) AuctionEventType {
+	switch status {
+	case AuctionStatusActive:
+		return AuctionEventActivated
+	case AuctionStatusRevealing:
+		return AuctionEventRevealing
+	case AuctionStatusEnded:
+		return AuctionEventEnded
+	case AuctionStatusCancelled:
+		return AuctionEventCancelled
+	case AuctionStatusSettling:
+		return AuctionEventSettlementStarted
+	case AuctionStatusSettled:
+		return AuctionEventSettled
+	case AuctionStatusSettlementFailed:
+		return AuctionEventSettlementFailed
+	default:
+		return AuctionEventStatusChanged
+	}
+}
+
+// AuctionEvent represents an entry of an auction's append-only event log. The
+// payload holds the auction fields the event set, keyed by their JSON names,
+// along with details such as the bids placed. Hidden holds the state that is
+// never sent to clients, such as the current bidder's proxy maximum.
+type AuctionEvent struct {
+	ID        string           `json:"id" db:"id"`
+	AuctionID string           `json:"auction_id" db:"auction_id"`
+	Sequence  int              `json:"sequence" db:"sequence"` // 1 for the created event
+	Type      AuctionEventType `json:"type" db:"type"`
+	Payload   json.RawMessage  `json:"payload" db:"payload"`
+	Hidden    json.RawMessage  `json:"-" db:"hidden"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+}
+
+// AuctionEventListResponse represents the event log of an auction
+type AuctionEventListResponse struct {
+	AuctionID string         `json:"auction_id"`
+	Events    []AuctionEvent `json:"events"`
+}
@@ -7,27 +7,31 @@ import (
 
 // NFT represents an NFT (Non-Fungible Token) in the system
 type NFT struct {
-	ID          string          `json:"id" db:"id"`
-	WalletID    string          `json:"wallet_id" db:"wallet_id"`
-	TokenID     string          `json:"token_id" db:"token_id"`
-	InscriptionID string        `json:"inscription_id" db:"inscription_id"`
-	Collection  string          `json:"collection" db:"collection"`
-	Title       string          `json:"title" db:"title"`
-	Description string          `json:"description" db:"description"`
-	ImageURL    string          `json:"image_url" db:"image_url"`
-	ContentURL  string          `json:"content_url" db:"content_url"`
-	Metadata    json.RawMessage `json:"metadata" db:"metadata"`
-	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
-	AuctionID   *string         `json:"auction_id,omitempty" db:"auction_id"`
+	ID            string          `json:"id" db:"id"`
+	WalletID      string          `json:"wallet_id" db:"wallet_id"`
+	TokenID       string          `json:"token_id" db:"token_id"`
+	InscriptionID string          `json:"inscription_id" db:"inscription_id"`
+	Collection    string          `json:"collection" db:"collection"`
+	Title         string          `json:"title" db:"title"`
+	Description   string          `json:"description" db:"description"`
+	ImageURL      string          `json:"image_url" db:"image_url"`
+	ContentURL    string          `json:"content_url" db:"content_url"`
+	Metadata      json.RawMessage `json:"metadata" db:"metadata"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+	AuctionID     *string         `json:"auction_id,omitempty" db:"auction_id"`
+	Location      string          `json:"location" db:"location"` // satpoint, txid:vout:offset
+	ContentType   string          `json:"content_type" db:"content_type"`
+	GenesisHeight int64           `json:"genesis_height" db:"genesis_height"`
+	SatNumber     *int64          `json:"sat_number,omitempty" db:"sat_number"`
 }
 
 // NFTListResponse represents the response for listing NFTs
 type NFTListResponse struct {
-	NFTs       []NFT  `json:"nfts"`
-	TotalCount int    `json:"total_count"`
-	Page       int    `json:"page"`
-	PageSize   int    `json:"page_size"`
+	NFTs       []NFT `json:"nfts"`
+	TotalCount int   `json:"total_count"`
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
 }
 
 // NFTParams represents the parameters for filtering NFTs
@@ -38,4 +42,14 @@ type NFTParams struct {
 	OnAuction  *bool  `json:"on_auction"`
 	Page       int    `json:"page"`
 	PageSize   int    `json:"page_size"`
-} 
\ No newline at end of file
+}
+
+// CollectionStats represents the market summary of a collection
+type CollectionStats struct {
+	Collection  string `json:"collection" db:"collection"`
+	ItemCount   int    `json:"item_count" db:"item_count"`
+	ListedCount int    `json:"listed_count" db:"listed_count"`         // NFTs on an active fixed-price listing
+	FloorPrice  *int64 `json:"floor_price,omitempty" db:"floor_price"` // cheapest active listing, in satoshis
+	BestOffer   *int64 `json:"best_offer,omitempty" db:"best_offer"`   // highest open collection offer per item, in satoshis
+	OfferCount  int    `json:"offer_count" db:"offer_count"`           // items wanted by open collection offers
+}
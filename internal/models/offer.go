@@ -0,0 +1,158 @@
+package models
+
+import "time"
+
+// OfferStatus represents the status of an offer
+type OfferStatus string
+
+const (
+	// Offer PSBT built, waiting for the offerer's signatures
+	OfferStatusPending OfferStatus = "pending"
+	// Funded and signed, waiting for the owner to accept or reject it
+	OfferStatusOpen OfferStatus = "open"
+	// Accepted by the owner, waiting for their signature
+	OfferStatusAccepted  OfferStatus = "accepted"
+	OfferStatusRejected  OfferStatus = "rejected"
+	OfferStatusCancelled OfferStatus = "cancelled"
+	OfferStatusExpired   OfferStatus = "expired"
+	// The NFT moved or the offerer's funding UTXOs were spent
+	OfferStatusInvalidated OfferStatus = "invalidated"
+	// Settlement transaction broadcast, waiting for confirmations
+	OfferStatusSettling OfferStatus = "settling"
+	// Settlement transaction confirmed and the NFT transferred
+	OfferStatusSettled OfferStatus = "settled"
+)
+
+// Offer represents a bid on an NFT that is not on auction. The offer PSBT
+// transfers the inscription to the offerer and pays the owner; the offerer
+// signs their funding inputs up front and the owner signs the inscription
+// input when accepting.
+type Offer struct {
+	ID              string `json:"id" db:"id"`
+	NFTID           string `json:"nft_id" db:"nft_id"`
+	OffererID       string `json:"offerer_id" db:"offerer_id"`
+	WalletID        string `json:"wallet_id" db:"wallet_id"` // funds the offer
+	ReceiveWalletID string `json:"receive_wallet_id" db:"receive_wallet_id"`
+	OwnerWalletID   string `json:"owner_wallet_id" db:"owner_wallet_id"`
+	// Set when the owner filled a collection offer; the fill is accepted once signed
+	CollectionOfferID *string     `json:"collection_offer_id,omitempty" db:"collection_offer_id"`
+	Amount            int64       `json:"amount" db:"amount"`     // in satoshis
+	Location          string      `json:"location" db:"location"` // satpoint of the inscription when the offer was made
+	PSBT              string      `json:"psbt" db:"psbt"`
+	SettlementTxID    *string     `json:"settlement_txid,omitempty" db:"settlement_txid"`
+	Status            OfferStatus `json:"status" db:"status"`
+	Reason            string      `json:"reason,omitempty" db:"reason"` // why the offer was invalidated
+	ExpiresAt         time.Time   `json:"expires_at" db:"expires_at"`
+	AcceptedAt        *time.Time  `json:"accepted_at,omitempty" db:"accepted_at"`
+	CreatedAt         time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// CreateOfferRequest represents a request to make an offer on an NFT
+type CreateOfferRequest struct {
+	NFTID          string    `json:"nft_id"`
+	WalletID       string    `json:"wallet_id"`
+	ReceiveAddress string    `json:"receive_address,omitempty"` // defaults to the funding wallet
+	Amount         int64     `json:"amount"`                    // in satoshis
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// CreateOfferResponse represents a new offer and the offer PSBT prepared for
+// the offerer to sign
+type CreateOfferResponse struct {
+	Offer *Offer          `json:"offer"`
+	PSBT  *SettlementPSBT `json:"psbt"`
+}
+
+// SignOfferRequest represents a request to fund an offer with its signed PSBT
+type SignOfferRequest struct {
+	OfferID string `json:"offer_id"`
+	PSBT    string `json:"psbt"` // offer PSBT signed by the offerer
+}
+
+// FinalizeOfferRequest represents a request to settle an accepted offer
+type FinalizeOfferRequest struct {
+	OfferID string `json:"offer_id"`
+	PSBT    string `json:"psbt"` // offer PSBT signed by the owner
+}
+
+// OfferParams represents the parameters for filtering offers
+type OfferParams struct {
+	NFTID     string      `json:"nft_id"`
+	OffererID string      `json:"offerer_id"`
+	OwnerID   string      `json:"owner_id"` // offers on NFTs held by the user's wallets
+	Status    OfferStatus `json:"status"`
+	Page      int         `json:"page"`
+	PageSize  int         `json:"page_size"`
+}
+
+// OfferListResponse represents the response for listing offers
+type OfferListResponse struct {
+	Offers     []Offer `json:"offers"`
+	TotalCount int     `json:"total_count"`
+	Page       int     `json:"page"`
+	PageSize   int     `json:"page_size"`
+}
+
+// CollectionOfferStatus represents the status of a collection offer
+type CollectionOfferStatus string
+
+const (
+	CollectionOfferStatusOpen CollectionOfferStatus = "open"
+	// Every item has been taken by holders
+	CollectionOfferStatusFilled    CollectionOfferStatus = "filled"
+	CollectionOfferStatusCancelled CollectionOfferStatus = "cancelled"
+	CollectionOfferStatusExpired   CollectionOfferStatus = "expired"
+)
+
+// CollectionOffer represents a standing offer to buy a quantity of any NFTs
+// of a collection at a price per item. Each fill by a holder creates an Offer
+// on their NFT that settles like any other offer.
+type CollectionOffer struct {
+	ID              string                `json:"id" db:"id"`
+	Collection      string                `json:"collection" db:"collection"`
+	OffererID       string                `json:"offerer_id" db:"offerer_id"`
+	WalletID        string                `json:"wallet_id" db:"wallet_id"` // funds the fills
+	ReceiveWalletID string                `json:"receive_wallet_id" db:"receive_wallet_id"`
+	Price           int64                 `json:"price" db:"price"` // per item, in satoshis
+	Quantity        int                   `json:"quantity" db:"quantity"`
+	Remaining       int                   `json:"remaining" db:"remaining"` // items not taken by a live fill
+	Status          CollectionOfferStatus `json:"status" db:"status"`
+	ExpiresAt       time.Time             `json:"expires_at" db:"expires_at"`
+	CreatedAt       time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// CreateCollectionOfferRequest represents a request to make a collection offer
+type CreateCollectionOfferRequest struct {
+	Collection     string    `json:"collection"`
+	WalletID       string    `json:"wallet_id"`
+	ReceiveAddress string    `json:"receive_address,omitempty"` // defaults to the funding wallet
+	Price          int64     `json:"price"`                     // per item, in satoshis
+	Quantity       int       `json:"quantity"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// FillCollectionOfferRequest represents a request to sell an NFT into a
+// collection offer
+type FillCollectionOfferRequest struct {
+	CollectionOfferID string `json:"collection_offer_id"`
+	NFTID             string `json:"nft_id"`
+}
+
+// CollectionOfferParams represents the parameters for filtering collection offers
+type CollectionOfferParams struct {
+	Collection string                `json:"collection"`
+	OffererID  string                `json:"offerer_id"`
+	Status     CollectionOfferStatus `json:"status"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"page_size"`
+}
+
+// CollectionOfferListResponse represents the response for listing collection offers
+type CollectionOfferListResponse struct {
+	CollectionOffers []CollectionOffer `json:"collection_offers"`
+	TotalCount       int               `json:"total_count"`
+	Page             int               `json:"page"`
+	PageSize         int               `json:"page_size"`
+}
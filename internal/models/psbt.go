@@ -0,0 +1,37 @@
+package models
+
+// PSBT represents a decoded Partially Signed Bitcoin Transaction
+type PSBT struct {
+	TxID     string       `json:"txid"`
+	Version  int32        `json:"version"`
+	LockTime uint32       `json:"lock_time"`
+	Inputs   []PSBTInput  `json:"inputs"`
+	Outputs  []PSBTOutput `json:"outputs"`
+	Fee      *int64       `json:"fee,omitempty"` // in satoshis, only known if every input has a UTXO
+}
+
+// PSBTInput represents an input of a PSBT
+type PSBTInput struct {
+	TxID        string      `json:"txid"`
+	Vout        uint32      `json:"vout"`
+	Sequence    uint32      `json:"sequence"`
+	WitnessUTXO *PSBTOutput `json:"witness_utxo,omitempty"`
+	SighashType uint32      `json:"sighash_type"`
+	Signed      bool        `json:"signed"`
+	Finalized   bool        `json:"finalized"`
+}
+
+// PSBTOutput represents an output of a PSBT (or the UTXO spent by an input)
+type PSBTOutput struct {
+	Value   int64  `json:"value"` // in satoshis
+	Script  string `json:"script"`
+	Address string `json:"address,omitempty"`
+}
+
+// SettlementPSBT represents a purchase PSBT waiting for the buyer's signatures
+type SettlementPSBT struct {
+	PSBT         string `json:"psbt"`
+	TxID         string `json:"txid"`
+	Fee          int64  `json:"fee"` // in satoshis
+	InputsToSign []int  `json:"inputs_to_sign"`
+}
@@ -13,14 +13,26 @@ type User struct {
 	Emails    []Email   `json:"emails,omitempty"`
 }
 
+// AddressType represents the script type of a Bitcoin address
+type AddressType string
+
+const (
+	AddressTypeP2PKH  AddressType = "p2pkh"
+	AddressTypeP2SH   AddressType = "p2sh"
+	AddressTypeP2WPKH AddressType = "p2wpkh"
+	AddressTypeP2WSH  AddressType = "p2wsh"
+	AddressTypeP2TR   AddressType = "p2tr"
+)
+
 // Wallet represents a crypto wallet
 type Wallet struct {
-	ID        string    `json:"id" db:"id"`
-	UserID    string    `json:"user_id" db:"user_id"`
-	Address   string    `json:"address" db:"address"`
-	Type      string    `json:"type" db:"type"` // e.g., "bitcoin", "ethereum"
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID         string      `json:"id" db:"id"`
+	UserID     string      `json:"user_id" db:"user_id"`
+	Address    string      `json:"address" db:"address"`
+	Type       string      `json:"type" db:"type"` // e.g., "bitcoin", "ethereum"
+	ScriptType AddressType `json:"script_type" db:"script_type"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at" db:"updated_at"`
 }
 
 // Email represents an email address associated with a user
@@ -43,6 +55,17 @@ type EmailVerification struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+// AuthChallenge represents a server-issued message a wallet must sign to log in
+type AuthChallenge struct {
+	ID        string     `json:"id" db:"id"`
+	Address   string     `json:"address" db:"address"`
+	Nonce     string     `json:"nonce" db:"nonce"`
+	Message   string     `json:"message" db:"message"`
+	IssuedAt  time.Time  `json:"issued_at" db:"issued_at"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"-" db:"used_at"`
+}
+
 // AuthToken represents the authentication token response
 type AuthToken struct {
 	Token     string    `json:"token"`
@@ -50,6 +73,11 @@ type AuthToken struct {
 	User      *User     `json:"user,omitempty"`
 }
 
+// ChallengeRequest represents a request for a wallet login challenge
+type ChallengeRequest struct {
+	Address string `json:"address"`
+}
+
 // WalletAuthRequest represents a request to authenticate with a wallet
 type WalletAuthRequest struct {
 	Address   string `json:"address"`
@@ -66,4 +94,4 @@ type EmailAuthRequest struct {
 type EmailVerifyRequest struct {
 	Email string `json:"email"`
 	Code  string `json:"code"`
-} 
\ No newline at end of file
+}
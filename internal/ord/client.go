@@ -0,0 +1,175 @@
+package ord
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client is an Indexer backed by the JSON API of an ord server
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Client
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// idsPage represents a page of inscription IDs returned by the recursive endpoints
+type idsPage struct {
+	IDs  []string `json:"ids"`
+	More bool     `json:"more"`
+	Page int      `json:"page"`
+}
+
+// GetInscription retrieves an inscription by ID, or nil if it is unknown
+func (c *Client) GetInscription(id string) (*Inscription, error) {
+	inscription := &Inscription{}
+	found, err := c.getJSON("/inscription/"+id, inscription)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return inscription, nil
+}
+
+// GetInscriptionsByAddress retrieves the IDs of the inscriptions held by an address
+func (c *Client) GetInscriptionsByAddress(address string) ([]string, error) {
+	var response struct {
+		Inscriptions []string `json:"inscriptions"`
+	}
+	if _, err := c.getJSON("/address/"+address, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Inscriptions == nil {
+		return []string{}, nil
+	}
+
+	return response.Inscriptions, nil
+}
+
+// GetContent retrieves the content of an inscription and its content type
+func (c *Client) GetContent(id string) ([]byte, string, error) {
+	body, header, found, err := c.get("/content/"+id, "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !found {
+		return nil, "", fmt.Errorf("inscription %s not found", id)
+	}
+
+	return body, header.Get("Content-Type"), nil
+}
+
+// GetSat retrieves a sat by number, or nil if it is unknown
+func (c *Client) GetSat(number uint64) (*Sat, error) {
+	sat := &Sat{}
+	found, err := c.getJSON("/sat/"+strconv.FormatUint(number, 10), sat)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return sat, nil
+}
+
+// GetParents retrieves the IDs of the parents of an inscription
+func (c *Client) GetParents(id string) ([]string, error) {
+	return c.getAllIDs("/r/parents/" + id)
+}
+
+// GetChildren retrieves the IDs of the children of an inscription
+func (c *Client) GetChildren(id string) ([]string, error) {
+	return c.getAllIDs("/r/children/" + id)
+}
+
+// ContentURL returns the public URL of an inscription's content
+func (c *Client) ContentURL(id string) string {
+	return c.baseURL + "/content/" + id
+}
+
+// getAllIDs follows a paginated list of inscription IDs
+func (c *Client) getAllIDs(path string) ([]string, error) {
+	ids := []string{}
+
+	for page := 0; ; page++ {
+		var response idsPage
+		found, err := c.getJSON(path+"/"+strconv.Itoa(page), &response)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			break
+		}
+
+		ids = append(ids, response.IDs...)
+		if !response.More {
+			break
+		}
+	}
+
+	return ids, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response. It returns
+// false if the resource was not found.
+func (c *Client) getJSON(path string, v interface{}) (bool, error) {
+	body, _, found, err := c.get(path, "application/json")
+	if err != nil || !found {
+		return false, err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return false, fmt.Errorf("failed to decode response from %s: %w", path, err)
+	}
+
+	return true, nil
+}
+
+// get performs a GET request and returns the response body and headers. It
+// returns false if the resource was not found.
+func (c *Client) get(path, accept string) ([]byte, http.Header, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("request to %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, false, fmt.Errorf("request to %s failed with status %d: %s",
+			path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return body, resp.Header, true, nil
+}
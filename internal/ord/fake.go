@@ -0,0 +1,187 @@
+package ord
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FakeIndexer is an in-memory Indexer backed by fixtures, for tests and
+// local development
+type FakeIndexer struct {
+	mu           sync.Mutex
+	baseURL      string
+	inscriptions map[string]*Inscription
+	contents     map[string][]byte
+	sats         map[uint64]*Sat
+}
+
+// Fixtures represents the contents of a fixtures file
+type Fixtures struct {
+	Inscriptions []Inscription     `json:"inscriptions"`
+	Contents     map[string]string `json:"contents"` // inscription ID to content
+	Sats         []Sat             `json:"sats"`
+}
+
+// NewFakeIndexer creates a new FakeIndexer
+func NewFakeIndexer(baseURL string) *FakeIndexer {
+	return &FakeIndexer{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		inscriptions: make(map[string]*Inscription),
+		contents:     make(map[string][]byte),
+		sats:         make(map[uint64]*Sat),
+	}
+}
+
+// LoadFixtures loads inscriptions, contents and sats from a JSON file
+func (f *FakeIndexer) LoadFixtures(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read ord fixtures: %w", err)
+	}
+
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return fmt.Errorf("failed to parse ord fixtures: %w", err)
+	}
+
+	for i := range fixtures.Inscriptions {
+		f.AddInscription(fixtures.Inscriptions[i])
+	}
+	for id, content := range fixtures.Contents {
+		f.SetContent(id, []byte(content))
+	}
+	for i := range fixtures.Sats {
+		f.AddSat(fixtures.Sats[i])
+	}
+
+	return nil
+}
+
+// AddInscription adds or replaces an inscription
+func (f *FakeIndexer) AddInscription(inscription Inscription) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.inscriptions[inscription.ID] = &inscription
+}
+
+// SetContent sets the content of an inscription
+func (f *FakeIndexer) SetContent(id string, content []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.contents[id] = content
+}
+
+// AddSat adds or replaces a sat
+func (f *FakeIndexer) AddSat(sat Sat) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sats[sat.Number] = &sat
+}
+
+// TransferInscription moves an inscription to a new address and satpoint
+func (f *FakeIndexer) TransferInscription(id, address, satpoint string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	inscription, ok := f.inscriptions[id]
+	if !ok {
+		return fmt.Errorf("inscription %s not found", id)
+	}
+
+	inscription.Address = address
+	inscription.Satpoint = satpoint
+	return nil
+}
+
+// GetInscription retrieves an inscription by ID, or nil if it is unknown
+func (f *FakeIndexer) GetInscription(id string) (*Inscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	inscription, ok := f.inscriptions[id]
+	if !ok {
+		return nil, nil
+	}
+
+	inscriptionCopy := *inscription
+	return &inscriptionCopy, nil
+}
+
+// GetInscriptionsByAddress retrieves the IDs of the inscriptions held by an address
+func (f *FakeIndexer) GetInscriptionsByAddress(address string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ids := []string{}
+	for id, inscription := range f.inscriptions {
+		if inscription.Address == address {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// GetContent retrieves the content of an inscription and its content type
+func (f *FakeIndexer) GetContent(id string) ([]byte, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	inscription, ok := f.inscriptions[id]
+	if !ok {
+		return nil, "", fmt.Errorf("inscription %s not found", id)
+	}
+
+	return f.contents[id], inscription.ContentType, nil
+}
+
+// GetSat retrieves a sat by number, or nil if it is unknown
+func (f *FakeIndexer) GetSat(number uint64) (*Sat, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sat, ok := f.sats[number]
+	if !ok {
+		return nil, nil
+	}
+
+	satCopy := *sat
+	return &satCopy, nil
+}
+
+// GetParents retrieves the IDs of the parents of an inscription
+func (f *FakeIndexer) GetParents(id string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	inscription, ok := f.inscriptions[id]
+	if !ok {
+		return []string{}, nil
+	}
+
+	return append([]string{}, inscription.Parents...), nil
+}
+
+// GetChildren retrieves the IDs of the children of an inscription
+func (f *FakeIndexer) GetChildren(id string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	inscription, ok := f.inscriptions[id]
+	if !ok {
+		return []string{}, nil
+	}
+
+	return append([]string{}, inscription.Children...), nil
+}
+
+// ContentURL returns the public URL of an inscription's content
+func (f *FakeIndexer) ContentURL(id string) string {
+	return f.baseURL + "/content/" + id
+}
@@ -0,0 +1,75 @@
+package ord
+
+import (
+	"fmt"
+
+	"github.com/satonic/satonic-api/internal/config"
+)
+
+// Indexer provides access to an ord inscription index
+type Indexer interface {
+	// GetInscription retrieves an inscription by ID, or nil if it is unknown
+	GetInscription(id string) (*Inscription, error)
+
+	// GetInscriptionsByAddress retrieves the IDs of the inscriptions held by an address
+	GetInscriptionsByAddress(address string) ([]string, error)
+
+	// GetContent retrieves the content of an inscription and its content type
+	GetContent(id string) ([]byte, string, error)
+
+	// GetSat retrieves a sat by number, or nil if it is unknown
+	GetSat(number uint64) (*Sat, error)
+
+	// GetParents retrieves the IDs of the parents of an inscription
+	GetParents(id string) ([]string, error)
+
+	// GetChildren retrieves the IDs of the children of an inscription
+	GetChildren(id string) ([]string, error)
+
+	// ContentURL returns the public URL of an inscription's content
+	ContentURL(id string) string
+}
+
+// Inscription represents an inscription as reported by ord
+type Inscription struct {
+	ID            string   `json:"id"`
+	Number        int64    `json:"number"`
+	Address       string   `json:"address"`
+	ContentType   string   `json:"content_type"`
+	ContentLength int64    `json:"content_length"`
+	Height        int64    `json:"height"` // genesis block height
+	Sat           *uint64  `json:"sat"`
+	Satpoint      string   `json:"satpoint"` // current location, txid:vout:offset
+	Value         int64    `json:"value"`    // value of the output holding the inscription
+	Timestamp     int64    `json:"timestamp"`
+	Parents       []string `json:"parents"`
+	Children      []string `json:"children"`
+}
+
+// Sat represents a sat as reported by ord
+type Sat struct {
+	Number       uint64   `json:"number"`
+	Name         string   `json:"name"`
+	Rarity       string   `json:"rarity"`
+	Block        int64    `json:"block"`
+	Satpoint     string   `json:"satpoint"`
+	Inscriptions []string `json:"inscriptions"`
+}
+
+// New creates the indexer selected in the configuration
+func New(cfg config.OrdConfig) (Indexer, error) {
+	switch cfg.Backend {
+	case "", "ord":
+		return NewClient(cfg.URL), nil
+	case "fake":
+		indexer := NewFakeIndexer(cfg.URL)
+		if cfg.FixturesFile != "" {
+			if err := indexer.LoadFixtures(cfg.FixturesFile); err != nil {
+				return nil, err
+			}
+		}
+		return indexer, nil
+	default:
+		return nil, fmt.Errorf("unknown ord backend %q", cfg.Backend)
+	}
+}
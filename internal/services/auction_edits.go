@@ -0,0 +1,221 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// Sellers may edit the prices and times of an auction while it is a draft.
+// They may cancel it until it receives its first bid; after that only when
+// the platform sets a cancellation penalty, which is recorded on the auction.
+// The penalty is informational: it is not collected and does not stop the
+// seller from listing again, which is left to the operator.
+
+// getSellerAuction retrieves an auction owned by the user
+func (s *AuctionService) getSellerAuction(auctionID, userID string) (*models.Auction, *models.Wallet, error) {
+	auction, err := s.auctionRepo.GetByID(auctionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if auction == nil {
+		return nil, nil, fmt.Errorf("auction not found")
+	}
+
+	sellerWallet, err := s.bidderWallet(userID, auction.SellerWalletID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("only the seller can change the auction")
+	}
+
+	return auction, sellerWallet, nil
+}
+
+// UpdateAuction edits the prices and times of a draft auction. A new listing
+// PSBT is required when the price paid to the seller changes.
+func (s *AuctionService) UpdateAuction(req models.UpdateAuctionRequest, userID string) (*models.Auction, error) {
+	auction, sellerWallet, err := s.getSellerAuction(req.AuctionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if auction.Status != models.AuctionStatusDraft {
+		return nil, fmt.Errorf("only draft auctions can be edited")
+	}
+
+	// Apply the edits on top of the current settings
+	settings := models.CreateAuctionRequest{
+		Type:              auction.Type,
+		NFTID:             auction.NFTID,
+		StartPrice:        auction.StartPrice,
+		ReservePrice:      auction.ReservePrice,
+		BuyNowPrice:       auction.BuyNowPrice,
+		StartTime:         auction.StartTime,
+		EndTime:           auction.EndTime,
+		PSBT:              auction.PSBT,
+		ExtensionWindow:   auction.ExtensionWindow,
+		ExtensionLength:   auction.ExtensionLength,
+		MaxExtensions:     auction.MaxExtensions,
+		BidIncrement:      auction.BidIncrement,
+		FloorPrice:        auction.FloorPrice,
+		PriceSchedule:     auction.PriceSchedule,
+		PriceStepInterval: auction.PriceStepInterval,
+		RevealEndTime:     auction.RevealEndTime,
+		SealedPricing:     auction.SealedPricing,
+	}
+
+	previousPrice := auctionListingPrice(auction)
+
+	if req.StartPrice != nil {
+		settings.StartPrice = *req.StartPrice
+	}
+	if req.ReservePrice != nil {
+		settings.ReservePrice = req.ReservePrice
+	}
+	if req.BuyNowPrice != nil {
+		settings.BuyNowPrice = req.BuyNowPrice
+	}
+	if req.FloorPrice != nil {
+		settings.FloorPrice = req.FloorPrice
+	}
+	if req.StartTime != nil {
+		settings.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		settings.EndTime = *req.EndTime
+	}
+	if req.RevealEndTime != nil {
+		settings.RevealEndTime = req.RevealEndTime
+	}
+
+	// Edited auctions stay drafts until the scheduler starts them
+	if !settings.StartTime.After(time.Now()) {
+		return nil, fmt.Errorf("start time must be in the future")
+	}
+
+	if !settings.EndTime.After(settings.StartTime) {
+		return nil, fmt.Errorf("end time must be after the start time")
+	}
+
+	listingPrice, err := validateAuctionRequest(&settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the new PSBT
+	if req.PSBT != "" || listingPrice != previousPrice {
+		if req.PSBT == "" {
+			return nil, fmt.Errorf("a new listing PSBT for %d sats is required", listingPrice)
+		}
+
+		nft, err := s.nftRepo.GetByID(auction.NFTID)
+		if err != nil {
+			return nil, err
+		}
+
+		if nft == nil {
+			return nil, fmt.Errorf("NFT not found")
+		}
+
+		lot, err := s.auctionRepo.GetLot(auction.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.walletService.ValidatePSBT(req.PSBT, ListingPSBTParams{
+			InscriptionID: nft.InscriptionID,
+			Location:      nft.Location,
+			SellerAddress: sellerWallet.Address,
+			Price:         s.fees.listingProceeds(listingPrice, saleNFTs(nft, lot)),
+			Lot:           lotInscriptions(lot),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		settings.PSBT = req.PSBT
+	}
+
+	auction.StartPrice = settings.StartPrice
+	auction.ReservePrice = settings.ReservePrice
+	auction.BuyNowPrice = settings.BuyNowPrice
+	auction.FloorPrice = settings.FloorPrice
+	auction.StartTime = settings.StartTime
+	auction.EndTime = settings.EndTime
+	auction.RevealEndTime = settings.RevealEndTime
+	auction.PSBT = settings.PSBT
+
+	updated, err := s.auctionRepo.UpdateDraft(auction)
+	if err != nil {
+		return nil, err
+	}
+
+	if !updated {
+		return nil, fmt.Errorf("auction has already started")
+	}
+
+	// Fetch the full auction with NFT
+	auction, err = s.GetByID(auction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.notifier != nil {
+		s.notifier.NotifyAuction(auction.ID, "auction_update", auction)
+	}
+
+	return auction, nil
+}
+
+// CancelAuction cancels one of the user's auctions and releases its NFT.
+// Drafts and auctions without bids are cancelled freely; auctions with bids
+// only when the platform sets a cancellation penalty, which is only recorded.
+func (s *AuctionService) CancelAuction(auctionID, userID string) (*models.Auction, error) {
+	if _, _, err := s.getSellerAuction(auctionID, userID); err != nil {
+		return nil, err
+	}
+
+	var penalty *int64
+	err := s.auctionRepo.CancelBySeller(auctionID, func(auction *models.Auction, bids int) (*int64, error) {
+		switch auction.Status {
+		case models.AuctionStatusDraft:
+			return nil, nil
+		case models.AuctionStatusActive, models.AuctionStatusRevealing:
+		default:
+			return nil, fmt.Errorf("auction can no longer be cancelled")
+		}
+
+		if bids == 0 {
+			return nil, nil
+		}
+
+		if s.cfg.CancellationPenalty == nil {
+			return nil, fmt.Errorf("auction has bids and can no longer be cancelled")
+		}
+
+		// The penalty is a share of the high bid, or of the start price
+		// while sealed bids are still hidden
+		price := auction.StartPrice
+		if auction.CurrentBid != nil && *auction.CurrentBid > price {
+			price = *auction.CurrentBid
+		}
+
+		amount := percentOf(price, *s.cfg.CancellationPenalty)
+		penalty = &amount
+
+		return penalty, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyStatus(models.AuctionStatusUpdate{
+		AuctionID:     auctionID,
+		Status:        models.AuctionStatusCancelled,
+		Reason:        "cancelled by the seller",
+		CancelPenalty: penalty,
+	})
+
+	return s.GetByID(auctionID)
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// Every change to an auction appends an event to its log in the same
+// transaction. An event's payload holds the auction fields it set, by their
+// JSON names, so replaying the log from the created event onto an empty
+// auction yields its current row. State withheld from clients, such as proxy
+// maxima and the listing and settlement PSBTs, is recorded apart from the payload.
+
+// GetEvents retrieves the events of an auction after a sequence number, or
+// nil if the auction does not exist
+func (s *AuctionService) GetEvents(auctionID string, after int) (*models.AuctionEventListResponse, error) {
+	auction, err := s.auctionRepo.GetByID(auctionID)
+	if err != nil || auction == nil {
+		return nil, err
+	}
+
+	events, err := s.auctionRepo.GetEvents(auctionID, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuctionEventListResponse{
+		AuctionID: auctionID,
+		Events:    events,
+	}, nil
+}
+
+// RebuildAuction overwrites an auction's row with the replay of its events
+func (s *AuctionService) RebuildAuction(id string) (*models.Auction, error) {
+	if _, err := s.auctionRepo.RebuildAuction(id, ProjectAuction); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(id)
+}
+
+// ProjectAuction replays the event log of an auction, starting with its
+// creation, and returns the auction it describes
+func ProjectAuction(events []models.AuctionEvent) (*models.Auction, error) {
+	if len(events) == 0 || events[0].Type != models.AuctionEventCreated {
+		return nil, fmt.Errorf("auction has no created event")
+	}
+
+	auction := &models.Auction{}
+	for i, event := range events {
+		if event.Sequence != i+1 {
+			return nil, fmt.Errorf("auction event %d is missing", i+1)
+		}
+
+		if err := applyAuctionEvent(auction, event); err != nil {
+			return nil, fmt.Errorf("failed to apply auction event %d: %w", event.Sequence, err)
+		}
+	}
+
+	// Only the auction row is rebuilt
+	auction.NFT = nil
+	auction.Lot = nil
+	auction.Bids = nil
+	auction.Fees = nil
+	auction.CurrentPrice = nil
+	auction.MinimumBid = 0
+
+	return auction, nil
+}
+
+// applyAuctionEvent sets the fields an event recorded on an auction. Fields
+// missing from the event are left as they were.
+func applyAuctionEvent(auction *models.Auction, event models.AuctionEvent) error {
+	if err := json.Unmarshal(event.Payload, auction); err != nil {
+		return err
+	}
+
+	if event.Type != models.AuctionEventCreated {
+		auction.UpdatedAt = event.CreatedAt
+	}
+
+	if len(event.Hidden) == 0 {
+		return nil
+	}
+
+	// The hidden fields are not part of the auction's JSON, so they are set
+	// one by one. A null clears the field.
+	var hidden struct {
+		PSBT           json.RawMessage `json:"psbt"`
+		SettlementPSBT json.RawMessage `json:"settlement_psbt"`
+		ProxyMaxBid    json.RawMessage `json:"proxy_max_bid"`
+		ProxyWalletID  json.RawMessage `json:"proxy_wallet_id"`
+	}
+	if err := json.Unmarshal(event.Hidden, &hidden); err != nil {
+		return err
+	}
+
+	fields := []struct {
+		raw    json.RawMessage
+		target interface{}
+	}{
+		{hidden.PSBT, &auction.PSBT},
+		{hidden.SettlementPSBT, &auction.SettlementPSBT},
+		{hidden.ProxyMaxBid, &auction.ProxyMaxBid},
+		{hidden.ProxyWalletID, &auction.ProxyWalletID},
+	}
+	for _, field := range fields {
+		if field.raw == nil {
+			continue
+		}
+		if err := json.Unmarshal(field.raw, field.target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
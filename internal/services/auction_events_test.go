@@ -0,0 +1,165 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// testEventLog builds an auction event log the way the auction repository
+// records it
+type testEventLog struct {
+	t      *testing.T
+	events []models.AuctionEvent
+}
+
+// append records an event with its payload and hidden state at a time
+func (l *testEventLog) append(eventType models.AuctionEventType, at time.Time, payload interface{}, hidden map[string]interface{}) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		l.t.Fatal(err)
+	}
+
+	var hiddenJSON json.RawMessage
+	if hidden != nil {
+		if hiddenJSON, err = json.Marshal(hidden); err != nil {
+			l.t.Fatal(err)
+		}
+	}
+
+	l.events = append(l.events, models.AuctionEvent{
+		AuctionID: "auction",
+		Sequence:  len(l.events) + 1,
+		Type:      eventType,
+		Payload:   payloadJSON,
+		Hidden:    hiddenJSON,
+		CreatedAt: at,
+	})
+}
+
+func TestProjectAuction(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	amount := func(sats int64) *int64 { return &sats }
+	text := func(s string) *string { return &s }
+
+	history := &testEventLog{t: t}
+
+	created := &models.Auction{
+		ID:              "auction",
+		Type:            models.AuctionTypeEnglish,
+		NFTID:           "nft",
+		SellerWalletID:  "seller-wallet",
+		StartPrice:      10_000,
+		StartTime:       start,
+		EndTime:         at(60),
+		Status:          models.AuctionStatusActive,
+		PSBT:            "listing-psbt",
+		ExtensionWindow: 300,
+		ExtensionLength: 600,
+		MinimumBid:      10_000,
+		CreatedAt:       start,
+		UpdatedAt:       start,
+		NFT:             &models.NFT{ID: "nft", Collection: "punks"},
+	}
+	history.append(models.AuctionEventCreated, start, created, map[string]interface{}{"psbt": created.PSBT})
+
+	// The first bidder leaves a proxy
+	history.append(models.AuctionEventBidPlaced, at(10), map[string]interface{}{
+		"bids": []*models.Bid{
+			{ID: "bid-1", AuctionID: "auction", BidderID: "bidder-1", WalletID: "wallet-1", Amount: 10_000, MaxAmount: amount(50_000)},
+		},
+		"current_bid":       10_000,
+		"current_bidder_id": "bidder-1",
+	}, map[string]interface{}{"proxy_max_bid": 50_000, "proxy_wallet_id": "wallet-1"})
+
+	// A late plain bid loses to the proxy and extends the auction
+	history.append(models.AuctionEventBidPlaced, at(58), map[string]interface{}{
+		"bids": []*models.Bid{
+			{ID: "bid-2", AuctionID: "auction", BidderID: "bidder-2", WalletID: "wallet-2", Amount: 20_000},
+			{ID: "bid-3", AuctionID: "auction", BidderID: "bidder-1", WalletID: "wallet-1", Amount: 21_000, Automatic: true},
+		},
+		"current_bid":       21_000,
+		"current_bidder_id": "bidder-1",
+	}, map[string]interface{}{"proxy_max_bid": 50_000, "proxy_wallet_id": "wallet-1"})
+	history.append(models.AuctionEventExtended, at(58), map[string]interface{}{
+		"end_time":        at(70),
+		"extension_count": 1,
+	}, nil)
+
+	// A higher proxy takes the lead
+	history.append(models.AuctionEventBidPlaced, at(65), map[string]interface{}{
+		"bids": []*models.Bid{
+			{ID: "bid-4", AuctionID: "auction", BidderID: "bidder-1", WalletID: "wallet-1", Amount: 50_000, Automatic: true},
+			{ID: "bid-5", AuctionID: "auction", BidderID: "bidder-2", WalletID: "wallet-2", Amount: 51_000, MaxAmount: amount(80_000)},
+		},
+		"current_bid":       51_000,
+		"current_bidder_id": "bidder-2",
+	}, map[string]interface{}{"proxy_max_bid": 80_000, "proxy_wallet_id": "wallet-2"})
+
+	history.append(models.AuctionEventEnded, at(70), map[string]interface{}{"status": models.AuctionStatusEnded}, nil)
+
+	// The winner settles
+	history.append(models.AuctionEventSettlementPrepared, at(75),
+		map[string]interface{}{"settlement_wallet_id": "receive-wallet"},
+		map[string]interface{}{"settlement_psbt": "settlement-psbt"})
+	history.append(models.AuctionEventSettlementStarted, at(80), map[string]interface{}{
+		"status":          models.AuctionStatusSettling,
+		"settlement_txid": "txid",
+	}, nil)
+	history.append(models.AuctionEventSettled, at(90), map[string]interface{}{
+		"status":    models.AuctionStatusSettled,
+		"locations": map[string]string{"nft": "txid:0:0"},
+	}, nil)
+
+	want := &models.Auction{
+		ID:                 "auction",
+		Type:               models.AuctionTypeEnglish,
+		NFTID:              "nft",
+		SellerWalletID:     "seller-wallet",
+		StartPrice:         10_000,
+		CurrentBid:         amount(51_000),
+		CurrentBidderID:    text("bidder-2"),
+		StartTime:          start,
+		EndTime:            at(70),
+		Status:             models.AuctionStatusSettled,
+		PSBT:               "listing-psbt",
+		SettlementPSBT:     text("settlement-psbt"),
+		SettlementTxID:     text("txid"),
+		SettlementWalletID: text("receive-wallet"),
+		ExtensionWindow:    300,
+		ExtensionLength:    600,
+		ExtensionCount:     1,
+		ProxyMaxBid:        amount(80_000),
+		ProxyWalletID:      text("wallet-2"),
+		CreatedAt:          start,
+		UpdatedAt:          at(90),
+	}
+
+	got, err := ProjectAuction(history.events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Every prefix of the log replays as well
+	for i := 1; i < len(history.events); i++ {
+		if _, err := ProjectAuction(history.events[:i]); err != nil {
+			t.Errorf("replaying %d events: %v", i, err)
+		}
+	}
+
+	// A log must start with the created event and have no gaps
+	if _, err := ProjectAuction(history.events[1:]); err == nil {
+		t.Error("replayed a log without its created event")
+	}
+	gap := append(append([]models.AuctionEvent{}, history.events[:2]...), history.events[3:]...)
+	if _, err := ProjectAuction(gap); err == nil {
+		t.Error("replayed a log missing an event")
+	}
+}
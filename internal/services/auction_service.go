@@ -2,31 +2,65 @@ package services
 
 import (
 	"fmt"
+	"log"
+	"math"
 	"time"
 
+	"github.com/satonic/satonic-api/internal/config"
 	"github.com/satonic/satonic-api/internal/models"
 	"github.com/satonic/satonic-api/internal/store"
 )
 
+// AuctionNotifier pushes auction updates to subscribed clients
+type AuctionNotifier interface {
+	NotifyAuction(auctionID, messageType string, payload interface{})
+}
+
 // AuctionService handles auction operations
 type AuctionService struct {
-	auctionRepo *store.AuctionRepository
-	nftRepo     *store.NFTRepository
-	userRepo    *store.UserRepository
+	auctionRepo   *store.AuctionRepository
+	nftRepo       *store.NFTRepository
+	userRepo      *store.UserRepository
+	walletService *WalletService
+	notifier      AuctionNotifier
+	cfg           config.AuctionConfig
+	fees          feeSchedule
 }
 
 // NewAuctionService creates a new AuctionService
-func NewAuctionService(auctionRepo *store.AuctionRepository, nftRepo *store.NFTRepository, userRepo *store.UserRepository) *AuctionService {
+func NewAuctionService(auctionRepo *store.AuctionRepository, nftRepo *store.NFTRepository, userRepo *store.UserRepository, walletService *WalletService, cfg config.AuctionConfig, fees config.FeeConfig) *AuctionService {
 	return &AuctionService{
-		auctionRepo: auctionRepo,
-		nftRepo:     nftRepo,
-		userRepo:    userRepo,
+		auctionRepo:   auctionRepo,
+		nftRepo:       nftRepo,
+		userRepo:      userRepo,
+		walletService: walletService,
+		cfg:           cfg,
+		fees:          feeSchedule{cfg: fees},
+	}
+}
+
+// SetNotifier sets the notifier used to push auction status transitions
+func (s *AuctionService) SetNotifier(notifier AuctionNotifier) {
+	s.notifier = notifier
+}
+
+// notifyStatus pushes an auction status transition to subscribers
+func (s *AuctionService) notifyStatus(update models.AuctionStatusUpdate) {
+	if s.notifier != nil {
+		s.notifier.NotifyAuction(update.AuctionID, "auction_status", update)
 	}
 }
 
 // GetByID retrieves an auction by ID
 func (s *AuctionService) GetByID(id string) (*models.Auction, error) {
-	return s.auctionRepo.GetByIDWithNFT(id)
+	auction, err := s.auctionRepo.GetByIDWithNFT(id)
+	if err != nil || auction == nil {
+		return auction, err
+	}
+
+	s.setPrices(auction, time.Now())
+
+	return auction, nil
 }
 
 // List retrieves auctions based on filter parameters
@@ -36,6 +70,11 @@ func (s *AuctionService) List(params models.AuctionParams) (*models.AuctionListR
 		return nil, err
 	}
 
+	now := time.Now()
+	for i := range auctions {
+		s.setPrices(&auctions[i], now)
+	}
+
 	return &models.AuctionListResponse{
 		Auctions:   auctions,
 		TotalCount: total,
@@ -46,6 +85,14 @@ func (s *AuctionService) List(params models.AuctionParams) (*models.AuctionListR
 
 // Create creates a new auction
 func (s *AuctionService) Create(req models.CreateAuctionRequest, userID string) (*models.Auction, error) {
+	// The first NFT of a lot stands for the auction
+	if len(req.NFTIDs) > 0 {
+		if req.NFTID != "" && req.NFTID != req.NFTIDs[0] {
+			return nil, fmt.Errorf("set either nft_id or nft_ids")
+		}
+		req.NFTID = req.NFTIDs[0]
+	}
+
 	// Check if NFT exists and belongs to the user
 	nft, err := s.nftRepo.GetByID(req.NFTID)
 	if err != nil {
@@ -80,28 +127,55 @@ func (s *AuctionService) Create(req models.CreateAuctionRequest, userID string)
 		return nil, fmt.Errorf("NFT is not owned by the user")
 	}
 
-	// Validate the PSBT
-	walletService := NewWalletService()
-	valid, message, err := walletService.ValidatePSBT(req.PSBT, nft.InscriptionID, sellerWallet.Address, "")
+	// Gather the NFTs of a lot
+	var lot []models.NFT
+	if len(req.NFTIDs) > 1 {
+		lot, err = s.getLotNFTs(req.NFTIDs, sellerWallet.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate the auction settings
+	listingPrice, err := validateAuctionRequest(&req)
 	if err != nil {
 		return nil, err
 	}
 
-	if !valid {
-		return nil, fmt.Errorf("invalid PSBT: %s", message)
+	// Validate the PSBT, which pays the seller the listing price less fees
+	err = s.walletService.ValidatePSBT(req.PSBT, ListingPSBTParams{
+		InscriptionID: nft.InscriptionID,
+		Location:      nft.Location,
+		SellerAddress: sellerWallet.Address,
+		Price:         s.fees.listingProceeds(listingPrice, saleNFTs(nft, lot)),
+		Lot:           lotInscriptions(lot),
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Create auction
 	auction := &models.Auction{
-		NFTID:          req.NFTID,
-		SellerWalletID: sellerWallet.ID,
-		StartPrice:     req.StartPrice,
-		ReservePrice:   req.ReservePrice,
-		BuyNowPrice:    req.BuyNowPrice,
-		StartTime:      req.StartTime,
-		EndTime:        req.EndTime,
-		Status:         models.AuctionStatusDraft,
-		PSBT:           req.PSBT,
+		Type:              req.Type,
+		NFTID:             req.NFTID,
+		SellerWalletID:    sellerWallet.ID,
+		StartPrice:        req.StartPrice,
+		ReservePrice:      req.ReservePrice,
+		BuyNowPrice:       req.BuyNowPrice,
+		StartTime:         req.StartTime,
+		EndTime:           req.EndTime,
+		Status:            models.AuctionStatusDraft,
+		PSBT:              req.PSBT,
+		ExtensionWindow:   req.ExtensionWindow,
+		ExtensionLength:   req.ExtensionLength,
+		MaxExtensions:     req.MaxExtensions,
+		BidIncrement:      req.BidIncrement,
+		FloorPrice:        req.FloorPrice,
+		PriceSchedule:     req.PriceSchedule,
+		PriceStepInterval: req.PriceStepInterval,
+		RevealEndTime:     req.RevealEndTime,
+		SealedPricing:     req.SealedPricing,
+		Lot:               lot,
 	}
 
 	// If start time is in the past or now, set status to active
@@ -119,88 +193,696 @@ func (s *AuctionService) Create(req models.CreateAuctionRequest, userID string)
 	return s.GetByID(auction.ID)
 }
 
-// PlaceBid places a bid on an auction
-func (s *AuctionService) PlaceBid(req models.PlaceBidRequest, userID string) (*models.Bid, error) {
-	// Get the auction
-	auction, err := s.GetByID(req.AuctionID)
+// validateAuctionRequest checks the settings of an auction request, defaulting
+// its type, and returns the price its listing PSBT must pay the seller
+func validateAuctionRequest(req *models.CreateAuctionRequest) (int64, error) {
+	// Validate the soft close settings
+	if req.ExtensionWindow < 0 || req.ExtensionLength < 0 {
+		return 0, fmt.Errorf("soft close settings must not be negative")
+	}
+
+	if (req.ExtensionWindow > 0) != (req.ExtensionLength > 0) {
+		return 0, fmt.Errorf("extension window and extension length must be set together")
+	}
+
+	if req.MaxExtensions != nil && *req.MaxExtensions < 0 {
+		return 0, fmt.Errorf("max extensions must not be negative")
+	}
+
+	// Validate the bid increment override
+	if req.BidIncrement != nil {
+		if err := req.BidIncrement.Validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	// English auctions are the default
+	if req.Type == "" {
+		req.Type = models.AuctionTypeEnglish
+	}
+
+	if req.Type != models.AuctionTypeDutch &&
+		(req.FloorPrice != nil || req.PriceSchedule != "" || req.PriceStepInterval != 0) {
+		return 0, fmt.Errorf("price schedules only apply to Dutch auctions")
+	}
+
+	if req.Type != models.AuctionTypeSealed && (req.RevealEndTime != nil || req.SealedPricing != "") {
+		return 0, fmt.Errorf("reveal settings only apply to sealed-bid auctions")
+	}
+
+	// The listing PSBT pays the seller the lowest price the auction can sell at
+	listingPrice := req.StartPrice
+	switch req.Type {
+	case models.AuctionTypeEnglish:
+	case models.AuctionTypeDutch:
+		if err := validateDutchAuction(req); err != nil {
+			return 0, err
+		}
+		listingPrice = *req.FloorPrice
+	case models.AuctionTypeSealed:
+		if err := validateSealedAuction(req); err != nil {
+			return 0, err
+		}
+	case models.AuctionTypeFixedPrice:
+		if err := validateListing(req); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("unknown auction type %q", req.Type)
+	}
+
+	return listingPrice, nil
+}
+
+// validateDutchAuction checks the price schedule of a Dutch auction request
+// and defaults it to linear. English auction settings are rejected.
+func validateDutchAuction(req *models.CreateAuctionRequest) error {
+	if req.FloorPrice == nil || *req.FloorPrice <= 0 || *req.FloorPrice >= req.StartPrice {
+		return fmt.Errorf("floor price must be positive and below the start price")
+	}
+
+	if !req.EndTime.After(req.StartTime) {
+		return fmt.Errorf("end time must be after the start time")
+	}
+
+	if req.ReservePrice != nil || req.BuyNowPrice != nil || req.ExtensionWindow != 0 ||
+		req.MaxExtensions != nil || req.BidIncrement != nil {
+		return fmt.Errorf("Dutch auctions do not support reserve, buy now, soft close or bid increment settings")
+	}
+
+	switch req.PriceSchedule {
+	case "":
+		req.PriceSchedule = models.PriceScheduleLinear
+	case models.PriceScheduleLinear:
+	case models.PriceScheduleStepped:
+		step := time.Duration(req.PriceStepInterval) * time.Second
+		if step <= 0 || step > req.EndTime.Sub(req.StartTime) {
+			return fmt.Errorf("price step interval must be positive and fit between the start and end time")
+		}
+	default:
+		return fmt.Errorf("unknown price schedule %q", req.PriceSchedule)
+	}
+
+	if req.PriceSchedule == models.PriceScheduleLinear && req.PriceStepInterval != 0 {
+		return fmt.Errorf("price step interval only applies to stepped schedules")
+	}
+
+	return nil
+}
+
+// validateSealedAuction checks the phases of a sealed-bid auction request and
+// defaults its pricing to first price. Open bidding settings are rejected.
+func validateSealedAuction(req *models.CreateAuctionRequest) error {
+	if !req.EndTime.After(req.StartTime) {
+		return fmt.Errorf("end time must be after the start time")
+	}
+
+	if req.RevealEndTime == nil || !req.RevealEndTime.After(req.EndTime) {
+		return fmt.Errorf("reveal end time must be after the end time")
+	}
+
+	if req.BuyNowPrice != nil || req.ExtensionWindow != 0 || req.MaxExtensions != nil || req.BidIncrement != nil {
+		return fmt.Errorf("sealed-bid auctions do not support buy now, soft close or bid increment settings")
+	}
+
+	switch req.SealedPricing {
+	case "":
+		req.SealedPricing = models.SealedPricingFirstPrice
+	case models.SealedPricingFirstPrice, models.SealedPricingSecondPrice:
+	default:
+		return fmt.Errorf("unknown sealed pricing %q", req.SealedPricing)
+	}
+
+	return nil
+}
+
+// PlaceBid places a bid on an auction. A bid that buys a Dutch auction
+// comes back with the buyer's settlement PSBT.
+func (s *AuctionService) PlaceBid(req models.PlaceBidRequest, userID string) (*models.Bid, *models.SettlementPSBT, error) {
+	// Verify wallet belongs to user
+	bidderWallet, err := s.bidderWallet(userID, req.WalletID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A proxy maximum must cover the visible bid
+	maxAmount := req.Amount
+	if req.MaxAmount != nil {
+		if *req.MaxAmount < req.Amount {
+			return nil, nil, fmt.Errorf("max amount must be at least the bid amount")
+		}
+		maxAmount = *req.MaxAmount
+	}
+
+	// The wallet must have signed the bid
+	message, err := s.verifyBidSignature(bidderWallet, req.AuctionID, req.Amount, req.MaxAmount, req.BidSignature)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check that the wallet can settle the maximum
+	if err := s.checkBidFunding(userID, bidderWallet, req.AuctionID, maxAmount); err != nil {
+		return nil, nil, err
+	}
+
+	// Create bid
+	bid := &models.Bid{
+		AuctionID: req.AuctionID,
+		BidderID:  userID,
+		WalletID:  req.WalletID,
+		Amount:    req.Amount,
+		MaxAmount: req.MaxAmount,
+		Signature: &req.Signature,
+		Message:   &message,
+	}
+
+	// Validate and resolve the bid against the locked auction
+	var extension *models.AuctionExtension
+	var sold bool
+	err = s.auctionRepo.PlaceBid(req.AuctionID, func(auction *models.Auction) ([]*models.Bid, error) {
+		now := time.Now()
+
+		if auction.Type == models.AuctionTypeSealed {
+			return nil, fmt.Errorf("sealed-bid auctions take commitments, not bids")
+		}
+
+		if auction.Type == models.AuctionTypeFixedPrice {
+			return nil, fmt.Errorf("fixed-price listings can only be bought")
+		}
+
+		// The first purchase of a Dutch auction wins at the current price
+		if auction.Type == models.AuctionTypeDutch {
+			if bid.MaxAmount != nil {
+				return nil, fmt.Errorf("Dutch auctions do not support proxy bids")
+			}
+
+			price := dutchPrice(auction, now)
+			if err := validateBid(auction, bid, price, now); err != nil {
+				return nil, err
+			}
+
+			sellAuction(auction, bid, price, now)
+			sold = true
+
+			return []*models.Bid{bid}, nil
+		}
+
+		if err := validateBid(auction, bid, s.minimumBid(auction), now); err != nil {
+			return nil, err
+		}
+
+		bids := s.resolveBid(auction, bid)
+
+		if extendAuction(auction, now) {
+			extension = &models.AuctionExtension{
+				AuctionID:      auction.ID,
+				EndTime:        auction.EndTime,
+				ExtensionCount: auction.ExtensionCount,
+			}
+		}
+
+		return bids, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if extension != nil && s.notifier != nil {
+		s.notifier.NotifyAuction(extension.AuctionID, "auction_extended", extension)
+	}
+
+	if !sold {
+		return bid, nil, nil
+	}
+
+	// The buyer of a Dutch auction settles right away
+	s.notifyStatus(models.AuctionStatusUpdate{
+		AuctionID: bid.AuctionID,
+		Status:    models.AuctionStatusEnded,
+	})
+
+	settlement, err := s.PrepareSettlement(models.SettlementRequest{
+		AuctionID:      req.AuctionID,
+		ReceiveAddress: req.ReceiveAddress,
+	}, userID)
+	if err != nil {
+		log.Printf("error preparing Dutch settlement of auction %s: %v", req.AuctionID, err)
+		return bid, nil, nil
+	}
+
+	return bid, settlement, nil
+}
+
+// bidderWallet returns the user's wallet with the given ID
+func (s *AuctionService) bidderWallet(userID, walletID string) (*models.Wallet, error) {
+	wallets, err := s.userRepo.GetWalletsByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	if auction == nil {
-		return nil, fmt.Errorf("auction not found")
+	for i := range wallets {
+		if wallets[i].ID == walletID {
+			return &wallets[i], nil
+		}
 	}
 
-	// Check if auction is active
-	if auction.Status != models.AuctionStatusActive {
-		return nil, fmt.Errorf("auction is not active")
+	return nil, fmt.Errorf("wallet not found or not owned by user")
+}
+
+// checkBidFunding checks that a wallet can settle a bid on an auction on top
+// of the user's other leading bids placed from it
+func (s *AuctionService) checkBidFunding(userID string, wallet *models.Wallet, auctionID string, amount int64) error {
+	inscribed, err := s.nftRepo.GetInscribedOutpoints(wallet.ID)
+	if err != nil {
+		return err
 	}
 
-	// Check if auction has started
-	if time.Now().Before(auction.StartTime) {
-		return nil, fmt.Errorf("auction has not started yet")
+	funds, err := s.walletService.SettlementFunds(wallet.Address, inscribed)
+	if err != nil {
+		return err
 	}
 
-	// Check if auction has ended
-	if time.Now().After(auction.EndTime) {
-		return nil, fmt.Errorf("auction has ended")
+	committed, err := s.auctionRepo.GetLeadingBidTotal(userID, wallet.ID, auctionID)
+	if err != nil {
+		return err
 	}
 
-	// Check if bid amount is higher than current bid
-	if auction.CurrentBid != nil && req.Amount <= *auction.CurrentBid {
-		return nil, fmt.Errorf("bid amount must be higher than current bid")
+	if funds-committed < amount {
+		return fmt.Errorf("insufficient funds: the wallet can settle %d sats after fees and %d sats of leading bids",
+			max(funds-committed, 0), committed)
 	}
 
-	// Check if bid amount is at least the start price
-	if req.Amount < auction.StartPrice {
-		return nil, fmt.Errorf("bid amount must be at least the start price")
+	return nil
+}
+
+// resolveBid applies a validated bid to the locked auction and returns the
+// bids to record, in order. Proxies bid on their owner's behalf the way eBay
+// does: the higher maximum wins at one increment over the lower one, capped
+// at its own maximum, and the earlier bidder wins a tie.
+func (s *AuctionService) resolveBid(auction *models.Auction, bid *models.Bid) []*models.Bid {
+	bidMax := bid.Amount
+	if bid.MaxAmount != nil {
+		bidMax = *bid.MaxAmount
+	}
+
+	// First bid, or the current bidder raising their own bid
+	if auction.CurrentBid == nil || auction.CurrentBidderID == nil || *auction.CurrentBidderID == bid.BidderID {
+		if auction.CurrentBid != nil && auction.ProxyMaxBid != nil && *auction.ProxyMaxBid > bidMax {
+			bidMax = *auction.ProxyMaxBid
+		}
+
+		bid.Amount = reservePrice(auction, bid.Amount, bidMax)
+		setHighBid(auction, bid, bidMax)
+		return []*models.Bid{bid}
+	}
+
+	leaderMax := *auction.CurrentBid
+	if auction.ProxyMaxBid != nil {
+		leaderMax = *auction.ProxyMaxBid
+	}
+	schedule := s.incrementSchedule(auction)
+
+	// The new bid outbids the current bidder's proxy
+	if bidMax > leaderMax {
+		var bids []*models.Bid
+		if leaderMax > *auction.CurrentBid && auction.ProxyWalletID != nil {
+			bids = append(bids, proxyBid(auction, *auction.CurrentBidderID, *auction.ProxyWalletID, leaderMax))
+		}
+
+		amount := leaderMax + bidIncrement(schedule, leaderMax)
+		if amount > bidMax {
+			amount = bidMax
+		}
+		if amount > bid.Amount {
+			bid.Amount = amount
+		}
+
+		bid.Amount = reservePrice(auction, bid.Amount, bidMax)
+		setHighBid(auction, bid, bidMax)
+		return append(bids, bid)
+	}
+
+	// The current bidder's proxy outbids the new bid
+	bid.Amount = bidMax
+	bids := []*models.Bid{bid}
+
+	amount := bidMax + bidIncrement(schedule, bidMax)
+	if amount > leaderMax {
+		amount = leaderMax
+	}
+	amount = reservePrice(auction, amount, leaderMax)
+
+	if auction.ProxyWalletID != nil {
+		bids = append(bids, proxyBid(auction, *auction.CurrentBidderID, *auction.ProxyWalletID, amount))
 	}
+	auction.CurrentBid = &amount
+
+	return bids
+}
+
+// sellAuction records bid as the winning bid at price and closes the auction
+// to further bids at now
+func sellAuction(auction *models.Auction, bid *models.Bid, price int64, now time.Time) {
+	bid.Amount = price
+	setHighBid(auction, bid, price)
+	auction.EndTime = now
+	auction.Status = models.AuctionStatusEnded
+}
 
+// BuyNow buys an English auction at its buy-now price, or a fixed-price
+// listing at its price. The purchase is recorded and the auction closed in one
+// transaction, then the settlement PSBT is prepared for the buyer. If that
+// fails the buyer can still request it from the settlement endpoint.
+func (s *AuctionService) BuyNow(req models.BuyNowRequest, userID string) (*models.BuyNowResponse, error) {
 	// Verify wallet belongs to user
-	wallet, err := s.userRepo.GetWalletsByUserID(userID)
+	buyerWallet, err := s.bidderWallet(userID, req.WalletID)
 	if err != nil {
 		return nil, err
 	}
 
-	var bidderWallet *models.Wallet
-	for _, w := range wallet {
-		if w.ID == req.WalletID {
-			bidderWallet = &w
-			break
-		}
+	// Get the auction
+	auction, err := s.auctionRepo.GetByID(req.AuctionID)
+	if err != nil {
+		return nil, err
 	}
 
-	if bidderWallet == nil {
-		return nil, fmt.Errorf("wallet not found or not owned by user")
+	if auction == nil {
+		return nil, fmt.Errorf("auction not found")
 	}
 
-	// Check if bidder has enough balance
-	walletService := NewWalletService()
-	balance, err := walletService.GetBalance(bidderWallet.Address)
+	price, err := buyNowPrice(auction)
 	if err != nil {
 		return nil, err
 	}
 
-	if balance < req.Amount {
-		return nil, fmt.Errorf("insufficient balance")
+	// The wallet must have signed the price
+	message, err := s.verifyBidSignature(buyerWallet, req.AuctionID, price, nil, req.BidSignature)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that the wallet can settle the purchase
+	if err := s.checkBidFunding(userID, buyerWallet, req.AuctionID, price); err != nil {
+		return nil, err
 	}
 
-	// Create bid
 	bid := &models.Bid{
 		AuctionID: req.AuctionID,
 		BidderID:  userID,
 		WalletID:  req.WalletID,
-		Amount:    req.Amount,
-		Accepted:  true,
+		Signature: &req.Signature,
+		Message:   &message,
 	}
 
-	// Save bid
-	err = s.auctionRepo.CreateBid(bid)
+	// Record the purchase against the locked auction
+	err = s.auctionRepo.PlaceBid(req.AuctionID, func(auction *models.Auction) ([]*models.Bid, error) {
+		// The buyer signed the price, which must still hold
+		locked, err := buyNowPrice(auction)
+		if err != nil {
+			return nil, err
+		}
+
+		if locked != price {
+			return nil, fmt.Errorf("buy now price changed to %d sats", locked)
+		}
+		bid.Amount = price
+
+		now := time.Now()
+		if err := validateBid(auction, bid, price, now); err != nil {
+			return nil, err
+		}
+
+		if auction.CurrentBid != nil && *auction.CurrentBid >= price {
+			return nil, fmt.Errorf("current bid has reached the buy now price")
+		}
+
+		sellAuction(auction, bid, price, now)
+
+		return []*models.Bid{bid}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return bid, nil
+	s.notifyStatus(models.AuctionStatusUpdate{
+		AuctionID: req.AuctionID,
+		Status:    models.AuctionStatusEnded,
+		Reason:    "bought outright",
+	})
+
+	response := &models.BuyNowResponse{Bid: bid}
+
+	// Start the settlement flow for the buyer
+	settlement, err := s.PrepareSettlement(models.SettlementRequest{
+		AuctionID:      req.AuctionID,
+		ReceiveAddress: req.ReceiveAddress,
+	}, userID)
+	if err != nil {
+		log.Printf("error preparing buy now settlement of auction %s: %v", req.AuctionID, err)
+		return response, nil
+	}
+
+	response.Settlement = settlement
+
+	return response, nil
+}
+
+// buyNowPrice returns the price an auction can be bought at outright
+func buyNowPrice(auction *models.Auction) (int64, error) {
+	switch {
+	case auction.Type == models.AuctionTypeFixedPrice:
+		return auction.StartPrice, nil
+	case auction.Type == models.AuctionTypeEnglish && auction.BuyNowPrice != nil:
+		return *auction.BuyNowPrice, nil
+	default:
+		return 0, fmt.Errorf("auction has no buy now price")
+	}
+}
+
+// reservePrice raises a winning bid to the reserve price when its maximum
+// covers it
+func reservePrice(auction *models.Auction, amount, maxAmount int64) int64 {
+	if auction.ReservePrice == nil || amount >= *auction.ReservePrice {
+		return amount
+	}
+
+	if maxAmount < *auction.ReservePrice {
+		return maxAmount
+	}
+
+	return *auction.ReservePrice
+}
+
+// setHighBid makes bid the auction's high bid, keeping its maximum for later
+// automatic bids
+func setHighBid(auction *models.Auction, bid *models.Bid, maxAmount int64) {
+	amount := bid.Amount
+	auction.CurrentBid = &amount
+	auction.CurrentBidderID = &bid.BidderID
+	auction.ProxyMaxBid = nil
+	auction.ProxyWalletID = nil
+
+	if maxAmount > amount {
+		auction.ProxyMaxBid = &maxAmount
+		auction.ProxyWalletID = &bid.WalletID
+	}
+}
+
+// proxyBid creates an automatic bid on behalf of a bidder's proxy
+func proxyBid(auction *models.Auction, bidderID, walletID string, amount int64) *models.Bid {
+	return &models.Bid{
+		AuctionID: auction.ID,
+		BidderID:  bidderID,
+		WalletID:  walletID,
+		Amount:    amount,
+		Automatic: true,
+	}
+}
+
+// extendAuction applies the soft close rule for a bid placed at now. It pushes
+// the end time to ExtensionLength after the bid when the bid lands in the last
+// ExtensionWindow of the auction, and reports whether the auction was extended.
+func extendAuction(auction *models.Auction, now time.Time) bool {
+	if auction.ExtensionWindow <= 0 || auction.ExtensionLength <= 0 {
+		return false
+	}
+
+	if auction.MaxExtensions != nil && auction.ExtensionCount >= *auction.MaxExtensions {
+		return false
+	}
+
+	window := time.Duration(auction.ExtensionWindow) * time.Second
+	if auction.EndTime.Sub(now) > window {
+		return false
+	}
+
+	endTime := now.Add(time.Duration(auction.ExtensionLength) * time.Second)
+	if !endTime.After(auction.EndTime) {
+		return false
+	}
+
+	auction.EndTime = endTime
+	auction.ExtensionCount++
+
+	return true
+}
+
+// setPrices fills in the server-side prices of an auction at now
+func (s *AuctionService) setPrices(auction *models.Auction, now time.Time) {
+	switch auction.Type {
+	case models.AuctionTypeDutch:
+		// A Dutch auction only has a price while it can be bought
+		if auction.Status == models.AuctionStatusActive {
+			price := dutchPrice(auction, now)
+			auction.CurrentPrice = &price
+			auction.MinimumBid = price
+		}
+	case models.AuctionTypeSealed:
+		// Sealed bids only have to reach the start price
+		auction.MinimumBid = auction.StartPrice
+	case models.AuctionTypeFixedPrice:
+		price := auction.StartPrice
+		auction.CurrentPrice = &price
+		auction.MinimumBid = price
+	default:
+		auction.MinimumBid = s.minimumBid(auction)
+	}
+
+	// Show what a sale at the current price pays out
+	price := auction.StartPrice
+	if auction.CurrentBid != nil {
+		price = *auction.CurrentBid
+	} else if auction.CurrentPrice != nil {
+		price = *auction.CurrentPrice
+	}
+
+	nfts := saleNFTs(auction.NFT, auction.Lot)
+	fees, err := s.fees.settle(price, s.fees.listingProceeds(auctionListingPrice(auction), nfts), nfts)
+	if err == nil {
+		auction.Fees = fees
+	}
+}
+
+// dutchPrice returns the price of a Dutch auction at now. The price falls
+// from the start price at the start time to the floor price at the end time,
+// continuously or in equal steps. Prices are rounded up.
+func dutchPrice(auction *models.Auction, now time.Time) int64 {
+	floor := auction.StartPrice
+	if auction.FloorPrice != nil {
+		floor = *auction.FloorPrice
+	}
+
+	duration := auction.EndTime.Sub(auction.StartTime)
+	elapsed := now.Sub(auction.StartTime)
+	if elapsed <= 0 || duration <= 0 {
+		return auction.StartPrice
+	}
+	if elapsed >= duration {
+		return floor
+	}
+
+	drop := auction.StartPrice - floor
+
+	if auction.PriceSchedule == models.PriceScheduleStepped && auction.PriceStepInterval > 0 {
+		step := time.Duration(auction.PriceStepInterval) * time.Second
+		steps := int64(duration / step)
+		taken := int64(elapsed / step)
+		if taken >= steps {
+			return floor
+		}
+
+		return auction.StartPrice - drop*taken/steps
+	}
+
+	return auction.StartPrice - int64(float64(drop)*elapsed.Seconds()/duration.Seconds())
+}
+
+// minimumBid returns the lowest amount the auction accepts as its next bid
+func (s *AuctionService) minimumBid(auction *models.Auction) int64 {
+	if auction.CurrentBid == nil {
+		return auction.StartPrice
+	}
+
+	return *auction.CurrentBid + bidIncrement(s.incrementSchedule(auction), *auction.CurrentBid)
+}
+
+// incrementSchedule returns the bid increment schedule of an auction. Auctions
+// may override the platform schedule.
+func (s *AuctionService) incrementSchedule(auction *models.Auction) models.BidIncrement {
+	if auction.BidIncrement != nil {
+		return *auction.BidIncrement
+	}
+
+	return s.cfg.BidIncrement
+}
+
+// bidIncrement returns the minimum raise over price under a schedule. The
+// increment is always at least one satoshi.
+func bidIncrement(schedule models.BidIncrement, price int64) int64 {
+	var increment int64
+
+	switch schedule.Type {
+	case models.BidIncrementFixed:
+		increment = schedule.Amount
+	case models.BidIncrementPercent:
+		increment = percentOf(price, schedule.Percent)
+	case models.BidIncrementTiered:
+		// Use the highest tier the price has reached
+		for _, tier := range schedule.Tiers {
+			if price < tier.From {
+				break
+			}
+
+			if tier.Amount > 0 {
+				increment = tier.Amount
+			} else {
+				increment = percentOf(price, tier.Percent)
+			}
+		}
+	}
+
+	if increment < 1 {
+		increment = 1
+	}
+
+	return increment
+}
+
+// percentOf returns percent of an amount in satoshis, rounded up
+func percentOf(amount int64, percent float64) int64 {
+	return int64(math.Ceil(float64(amount) * percent / 100))
+}
+
+// validateBid checks a bid against the current state of an auction and the
+// minimum acceptable bid
+func validateBid(auction *models.Auction, bid *models.Bid, minimumBid int64, now time.Time) error {
+	// Check if auction is active
+	if auction.Status != models.AuctionStatusActive {
+		return fmt.Errorf("auction is not active")
+	}
+
+	// Check if auction has started
+	if now.Before(auction.StartTime) {
+		return fmt.Errorf("auction has not started yet")
+	}
+
+	// Check if auction has ended
+	if now.After(auction.EndTime) {
+		return fmt.Errorf("auction has ended")
+	}
+
+	// Check if bid amount is at least the start price
+	if auction.CurrentBid == nil && auction.Type != models.AuctionTypeDutch && bid.Amount < auction.StartPrice {
+		return fmt.Errorf("bid amount must be at least the start price")
+	}
+
+	// Check if bid amount reaches the minimum bid, or the current price of a
+	// Dutch auction
+	if bid.Amount < minimumBid {
+		return fmt.Errorf("bid amount must be at least %d sats", minimumBid)
+	}
+
+	return nil
 }
 
 // FinalizeAuction finalizes an auction
@@ -215,8 +897,8 @@ func (s *AuctionService) FinalizeAuction(req models.FinalizeAuctionRequest, user
 		return nil, fmt.Errorf("auction not found")
 	}
 
-	// Check if auction is active
-	if auction.Status != models.AuctionStatusActive {
+	// Check if auction is awaiting settlement
+	if !auction.Status.AwaitingSettlement() {
 		return nil, fmt.Errorf("auction is not active")
 	}
 
@@ -225,7 +907,9 @@ func (s *AuctionService) FinalizeAuction(req models.FinalizeAuctionRequest, user
 		auction.CurrentBid != nil &&
 		*auction.CurrentBid >= *auction.BuyNowPrice
 
-	if !time.Now().After(auction.EndTime) && !buyNowTriggered {
+	// Dutch auctions end as soon as they are bought
+	ended := auction.Status == models.AuctionStatusEnded || time.Now().After(auction.EndTime)
+	if !ended && !buyNowTriggered {
 		return nil, fmt.Errorf("auction has not ended yet")
 	}
 
@@ -258,59 +942,433 @@ func (s *AuctionService) FinalizeAuction(req models.FinalizeAuctionRequest, user
 		return nil, fmt.Errorf("only the winning bidder can finalize the auction")
 	}
 
-	// Validate the signature
-	// In a real implementation, this would complete the PSBT transaction
-	// by adding the winning bidder's signature
+	// Check that a settlement PSBT was prepared
+	if auction.SettlementPSBT == nil {
+		return nil, fmt.Errorf("settlement has not been prepared")
+	}
 
-	// Complete the auction
-	err = s.auctionRepo.CompleteAuction(auction.ID, models.AuctionStatusCompleted)
+	// The settlement must pay the fees due at the winning price, in case
+	// they changed since it was prepared
+	fees, err := s.settlementFees(auction)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.walletService.VerifyPayouts(*auction.SettlementPSBT, s.fees.payouts(fees)); err != nil {
+		return nil, err
+	}
+
+	// Merge the buyer's signatures and extract the final transaction
+	rawTx, txid, err := s.walletService.FinalizePurchasePSBT(*auction.SettlementPSBT, req.PSBT)
+	if err != nil {
+		return nil, err
+	}
+
+	// Broadcast the settlement transaction
+	broadcastTxID, err := s.walletService.BroadcastTransaction(rawTx)
+	if err != nil {
+		return nil, err
+	}
+
+	if broadcastTxID != "" {
+		txid = broadcastTxID
+	}
+
+	// Wait for the settlement transaction to confirm
+	err = s.auctionRepo.StartSettlement(auction.ID, txid)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update auction status
-	auction.Status = models.AuctionStatusCompleted
+	auction.Status = models.AuctionStatusSettling
+	auction.SettlementTxID = &txid
+
+	s.notifyStatus(models.AuctionStatusUpdate{
+		AuctionID:      auction.ID,
+		Status:         auction.Status,
+		SettlementTxID: auction.SettlementTxID,
+	})
 
 	return auction, nil
 }
 
+// PrepareSettlement builds the settlement PSBT for the winning bidder to sign
+func (s *AuctionService) PrepareSettlement(req models.SettlementRequest, userID string) (*models.SettlementPSBT, error) {
+	// Get the auction
+	auction, err := s.GetByID(req.AuctionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if auction == nil {
+		return nil, fmt.Errorf("auction not found")
+	}
+
+	// Check if auction is awaiting settlement
+	if !auction.Status.AwaitingSettlement() {
+		return nil, fmt.Errorf("auction is not active")
+	}
+
+	// Check if auction has ended or has a "Buy Now" price that was met
+	buyNowTriggered := auction.BuyNowPrice != nil &&
+		auction.CurrentBid != nil &&
+		*auction.CurrentBid >= *auction.BuyNowPrice
+
+	// Dutch auctions end as soon as they are bought
+	ended := auction.Status == models.AuctionStatusEnded || time.Now().After(auction.EndTime)
+	if !ended && !buyNowTriggered {
+		return nil, fmt.Errorf("auction has not ended yet")
+	}
+
+	// Check that there is a winning bid
+	if auction.CurrentBid == nil || auction.CurrentBidderID == nil {
+		return nil, fmt.Errorf("auction has no bids")
+	}
+
+	if auction.ReservePrice != nil && *auction.CurrentBid < *auction.ReservePrice {
+		return nil, fmt.Errorf("reserve price was not met")
+	}
+
+	if *auction.CurrentBidderID != userID {
+		return nil, fmt.Errorf("only the winning bidder can settle the auction")
+	}
+
+	// Find the wallet the winning bid was placed from
+	var winningBid *models.Bid
+	for i := range auction.Bids {
+		bid := &auction.Bids[i]
+		if bid.BidderID == userID && bid.Amount == *auction.CurrentBid {
+			winningBid = bid
+			break
+		}
+	}
+
+	if winningBid == nil {
+		return nil, fmt.Errorf("winning bid not found")
+	}
+
+	wallet, err := s.userRepo.GetWalletByID(winningBid.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		return nil, fmt.Errorf("winning wallet not found")
+	}
+
+	// The inscription must go to one of the winner's wallets so it can be tracked
+	receiveWallet := wallet
+	if req.ReceiveAddress != "" && req.ReceiveAddress != wallet.Address {
+		wallets, err := s.userRepo.GetWalletsByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+
+		receiveWallet = nil
+		for i := range wallets {
+			if wallets[i].Address == req.ReceiveAddress {
+				receiveWallet = &wallets[i]
+				break
+			}
+		}
+
+		if receiveWallet == nil {
+			return nil, fmt.Errorf("receive address must belong to one of your wallets")
+		}
+	}
+
+	if auction.NFT == nil || auction.NFT.Location == "" {
+		return nil, fmt.Errorf("NFT location is unknown")
+	}
+
+	lot, err := lotLocations(auction.Lot)
+	if err != nil {
+		return nil, err
+	}
+
+	fees, err := s.settlementFees(auction)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave the winner's inscriptions where they are
+	inscribed, err := s.nftRepo.GetInscribedOutpoints(wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the settlement PSBT
+	settlement, err := s.walletService.BuildPurchasePSBT(PurchasePSBTParams{
+		ListingPSBT:    auction.PSBT,
+		Location:       auction.NFT.Location,
+		Lot:            lot,
+		BuyerAddress:   wallet.Address,
+		ReceiveAddress: receiveWallet.Address,
+		Price:          *auction.CurrentBid,
+		Payouts:        s.fees.payouts(fees),
+		Inscribed:      inscribed,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Save the settlement PSBT so the signed copy can be checked against it
+	err = s.auctionRepo.SetSettlementPSBT(auction.ID, settlement.PSBT, receiveWallet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return settlement, nil
+}
+
 // GetActiveAuctions retrieves all active auctions
 func (s *AuctionService) GetActiveAuctions() ([]models.Auction, error) {
-	return s.auctionRepo.GetActiveAuctions()
+	auctions, err := s.auctionRepo.GetActiveAuctions()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range auctions {
+		s.setPrices(&auctions[i], now)
+	}
+
+	return auctions, nil
+}
+
+// ActivateDueAuctions activates draft auctions whose start time has passed
+func (s *AuctionService) ActivateDueAuctions(now time.Time) error {
+	// Get all due drafts
+	auctions, err := s.auctionRepo.GetDueDraftAuctions(now)
+	if err != nil {
+		return err
+	}
+
+	for _, auction := range auctions {
+		activated, err := s.auctionRepo.TransitionStatus(auction.ID, models.AuctionStatusDraft, models.AuctionStatusActive)
+		if err != nil {
+			return err
+		}
+
+		if activated {
+			s.notifyStatus(models.AuctionStatusUpdate{
+				AuctionID: auction.ID,
+				Status:    models.AuctionStatusActive,
+			})
+		}
+	}
+
+	return nil
 }
 
-// ProcessEndedAuctions processes auctions that have ended but not yet finalized
-func (s *AuctionService) ProcessEndedAuctions() error {
+// ProcessEndedAuctions closes active auctions whose end time has passed.
+// Auctions without a winning bid are cancelled, the others wait for the
+// winner to settle. Sealed-bid auctions move on to their reveal phase.
+func (s *AuctionService) ProcessEndedAuctions(now time.Time) error {
 	// Get all ended auctions
-	auctions, err := s.auctionRepo.GetEndedAuctions()
+	auctions, err := s.auctionRepo.GetEndedAuctions(now)
 	if err != nil {
 		return err
 	}
 
 	for _, auction := range auctions {
-		// Check if there are any bids
-		if auction.CurrentBid == nil || auction.CurrentBidderID == nil {
-			// No bids, cancel the auction
-			err = s.auctionRepo.CompleteAuction(auction.ID, models.AuctionStatusCancelled)
+		// Sealed-bid auctions stop taking commitments and start the reveal phase
+		if auction.Type == models.AuctionTypeSealed {
+			revealing, err := s.auctionRepo.TransitionStatus(auction.ID, models.AuctionStatusActive, models.AuctionStatusRevealing)
 			if err != nil {
 				return err
 			}
+
+			if revealing {
+				s.notifyStatus(models.AuctionStatusUpdate{
+					AuctionID: auction.ID,
+					Status:    models.AuctionStatusRevealing,
+				})
+			}
 			continue
 		}
 
-		// Check if reserve price was met
-		if auction.ReservePrice != nil && *auction.CurrentBid < *auction.ReservePrice {
-			// Reserve not met, cancel the auction
-			err = s.auctionRepo.CompleteAuction(auction.ID, models.AuctionStatusCancelled)
+		// Close the auction unless a late bid extended it
+		status, err := s.auctionRepo.EndAuction(auction.ID, now)
+		if err != nil {
+			return err
+		}
+
+		if status != "" {
+			s.notifyStatus(models.AuctionStatusUpdate{
+				AuctionID: auction.ID,
+				Status:    status,
+			})
+		}
+	}
+
+	return nil
+}
+
+// ExpireSettlements cancels the sales whose winner did not broadcast a
+// settlement within the settlement timeout of the end of the auction, and
+// releases their NFTs
+func (s *AuctionService) ExpireSettlements(now time.Time) error {
+	if s.cfg.SettlementTimeout <= 0 {
+		return nil
+	}
+
+	endedBefore := now.Add(-time.Duration(s.cfg.SettlementTimeout) * time.Second)
+	auctions, err := s.auctionRepo.GetUnsettledAuctions(endedBefore)
+	if err != nil {
+		return err
+	}
+
+	for _, auction := range auctions {
+		expired, err := s.auctionRepo.ExpireSettlement(auction.ID, endedBefore, now)
+		if err != nil {
+			return err
+		}
+
+		if expired {
+			s.notifyStatus(models.AuctionStatusUpdate{
+				AuctionID: auction.ID,
+				Status:    models.AuctionStatusCancelled,
+				Reason:    "the winner did not settle in time",
+			})
+		}
+	}
+
+	return nil
+}
+
+// PublishDutchPrices pushes the current price of active Dutch auctions to
+// subscribers when it changed since the previous pass at last
+func (s *AuctionService) PublishDutchPrices(now, last time.Time) error {
+	if s.notifier == nil {
+		return nil
+	}
+
+	auctions, err := s.auctionRepo.GetActiveAuctions()
+	if err != nil {
+		return err
+	}
+
+	for i := range auctions {
+		auction := &auctions[i]
+		if auction.Type != models.AuctionTypeDutch || now.Before(auction.StartTime) {
+			continue
+		}
+
+		price := dutchPrice(auction, now)
+		if !last.IsZero() && dutchPrice(auction, last) == price {
+			continue
+		}
+
+		s.notifier.NotifyAuction(auction.ID, "price_update", models.AuctionPriceUpdate{
+			AuctionID:    auction.ID,
+			CurrentPrice: price,
+		})
+	}
+
+	return nil
+}
+
+// CheckSettlements moves settling auctions to settled once their settlement
+// transaction has enough confirmations, or to settlement_failed when it was
+// replaced or dropped
+func (s *AuctionService) CheckSettlements(requiredConfirmations int) error {
+	auctions, err := s.auctionRepo.GetSettlingAuctions()
+	if err != nil {
+		return err
+	}
+
+	for _, auction := range auctions {
+		if err := s.checkSettlement(auction, requiredConfirmations); err != nil {
+			log.Printf("error checking settlement of auction %s: %v", auction.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// checkSettlement checks the settlement transaction of a single auction
+func (s *AuctionService) checkSettlement(auction models.Auction, requiredConfirmations int) error {
+	if auction.SettlementTxID == nil || auction.SettlementPSBT == nil || auction.SettlementWalletID == nil {
+		return fmt.Errorf("auction has no settlement transaction")
+	}
+
+	// Get the NFT
+	nft, err := s.nftRepo.GetByID(auction.NFTID)
+	if err != nil {
+		return err
+	}
+
+	if nft == nil {
+		return fmt.Errorf("NFT not found")
+	}
+
+	// Lots transfer every NFT
+	nfts := []models.NFT{*nft}
+	lot, err := s.auctionRepo.GetLot(auction.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(lot) > 0 {
+		nfts = lot
+	}
+
+	status, err := s.walletService.GetSettlementStatus(*auction.SettlementTxID, nft.Location)
+	if err != nil {
+		return err
+	}
+
+	update := models.AuctionStatusUpdate{
+		AuctionID:      auction.ID,
+		SettlementTxID: auction.SettlementTxID,
+	}
+
+	switch {
+	case status.ConflictTxID != "" || status.Dropped:
+		// The settlement will never confirm, let the winner settle again
+		failed, err := s.auctionRepo.TransitionStatus(auction.ID, models.AuctionStatusSettling, models.AuctionStatusSettlementFailed)
+		if err != nil {
+			return err
+		}
+
+		// Another check or a new settlement got there first
+		if !failed {
+			return nil
+		}
+
+		update.Status = models.AuctionStatusSettlementFailed
+		if status.Dropped {
+			update.Reason = "settlement transaction was dropped from the mempool"
+		} else {
+			update.Reason = "settlement transaction was replaced by " + status.ConflictTxID
+		}
+
+	case status.Confirmations >= int64(requiredConfirmations):
+		locations := make(map[string]string)
+		for _, nft := range nfts {
+			location, err := SettlementLocation(*auction.SettlementPSBT, nft.Location, *auction.SettlementTxID)
 			if err != nil {
 				return err
 			}
-			continue
+			locations[nft.ID] = location
 		}
 
-		// Auction has a winning bid, but needs to be finalized by the bidder
-		// Send notification to the bidder (in a real implementation)
+		// Transfer the NFTs to the buyer's wallet
+		err = s.auctionRepo.CompleteSettlement(auction.ID, *auction.SettlementWalletID, locations)
+		if err != nil {
+			return err
+		}
+
+		update.Status = models.AuctionStatusSettled
+		update.Confirmations = status.Confirmations
+
+	default:
+		return nil
 	}
 
+	s.notifyStatus(update)
+
 	return nil
 }
@@ -0,0 +1,405 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/google/uuid"
+	"github.com/satonic/satonic-api/internal/chain"
+	"github.com/satonic/satonic-api/internal/config"
+	"github.com/satonic/satonic-api/internal/models"
+	"github.com/satonic/satonic-api/internal/store"
+)
+
+// testDSNEnv names the connection string of a Postgres database with
+// store/schema.sql applied. Tests needing a database are skipped without it.
+const testDSNEnv = "SATONIC_TEST_DSN"
+
+// testBidder is a user bidding from a P2WPKH wallet it holds the key of
+type testBidder struct {
+	user   *models.User
+	wallet *models.Wallet
+	key    *btcec.PrivateKey
+}
+
+// testDatabase connects to the test database, skipping the test without one
+func testDatabase(t *testing.T) *store.Database {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	db, err := store.NewDatabaseFromDSN(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// newTestBidder creates a user with a wallet funded on the fake backend, so
+// that it can settle any of the test bids
+func newTestBidder(t *testing.T, userRepo *store.UserRepository, fake *chain.FakeBackend) *testBidder {
+	t.Helper()
+
+	key, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(key.PubKey().SerializeCompressed()), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := userRepo.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallet, err := userRepo.AddWallet(user.ID, addr.EncodeAddress(), "test", models.AddressTypeP2WPKH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []int64{1000, 100_000_000} {
+		txid := chainhash.DoubleHashH([]byte(uuid.New().String()))
+		fake.AddUTXO(wallet.Address, chain.UTXO{TxID: txid.String(), Value: value})
+	}
+
+	return &testBidder{user: user, wallet: wallet, key: key}
+}
+
+// newTestNFT creates an NFT held by the wallet
+func newTestNFT(t *testing.T, nftRepo *store.NFTRepository, wallet *models.Wallet, title string) *models.NFT {
+	t.Helper()
+
+	nft := &models.NFT{
+		WalletID:      wallet.ID,
+		TokenID:       uuid.New().String(),
+		InscriptionID: uuid.New().String() + "i0",
+		Collection:    "test",
+		Title:         title,
+	}
+	if err := nftRepo.Create(nft); err != nil {
+		t.Fatal(err)
+	}
+
+	return nft
+}
+
+func TestPlaceBidConcurrent(t *testing.T) {
+	db := testDatabase(t)
+
+	auctionRepo := store.NewAuctionRepository(db)
+	nftRepo := store.NewNFTRepository(db)
+	userRepo := store.NewUserRepository(db)
+
+	fake := chain.NewFakeBackend(&chaincfg.MainNetParams)
+	fake.SetFeeEstimates(chain.FeeEstimates{settlementConfTarget: 5})
+	walletService := NewWalletService(config.BitcoinConfig{}, fake)
+
+	increment := models.BidIncrement{Type: models.BidIncrementFixed, Amount: 100}
+	auctionService := NewAuctionService(auctionRepo, nftRepo, userRepo, walletService,
+		config.AuctionConfig{BidIncrement: increment}, config.FeeConfig{})
+
+	seller := newTestBidder(t, userRepo, fake)
+	nft := newTestNFT(t, nftRepo, seller.wallet, "Concurrent bids")
+
+	now := time.Now()
+	auction := &models.Auction{
+		Type:           models.AuctionTypeEnglish,
+		NFTID:          nft.ID,
+		SellerWalletID: seller.wallet.ID,
+		StartPrice:     10_000,
+		StartTime:      now.Add(-time.Minute),
+		EndTime:        now.Add(time.Hour),
+		Status:         models.AuctionStatusActive,
+		PSBT:           "listing",
+	}
+	if err := auctionRepo.Create(auction); err != nil {
+		t.Fatal(err)
+	}
+
+	bidders := make([]*testBidder, 20)
+	for i := range bidders {
+		bidders[i] = newTestBidder(t, userRepo, fake)
+	}
+
+	// Fire every bid at once, in random order, with distinct amounts
+	const bids = 300
+	amounts := rand.Perm(bids)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted := 0
+	start := make(chan struct{})
+
+	for i, step := range amounts {
+		bidder := bidders[i%len(bidders)]
+		amount := auction.StartPrice + int64(step)*150
+
+		timestamp := time.Now()
+		nonce := fmt.Sprintf("concurrent-%04d", i)
+		message := walletService.GenerateBidMessage(auction.ID, bidder.wallet.Address, nonce, amount, nil, timestamp)
+		signature := ecdsa.SignCompact(bidder.key, bip137MessageHash(message), true)
+
+		req := models.PlaceBidRequest{
+			AuctionID: auction.ID,
+			WalletID:  bidder.wallet.ID,
+			Amount:    amount,
+			BidSignature: models.BidSignature{
+				Signature: base64.StdEncoding.EncodeToString(signature),
+				Nonce:     nonce,
+				Timestamp: timestamp,
+			},
+		}
+
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			<-start
+
+			if _, _, err := auctionService.PlaceBid(req, userID); err == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}(bidder.user.ID)
+	}
+
+	close(start)
+	wg.Wait()
+
+	if accepted == 0 {
+		t.Fatal("no bid was accepted")
+	}
+
+	recorded, err := auctionRepo.GetBidsByAuctionID(auction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != accepted {
+		t.Fatalf("recorded %d bids, %d were accepted", len(recorded), accepted)
+	}
+	for _, bid := range recorded {
+		if !bid.Accepted {
+			t.Errorf("bid %s was recorded but not accepted", bid.ID)
+		}
+	}
+
+	// Every accepted bid raised the previous high bid
+	history, err := auctionRepo.GetSignedBids(auction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var high int64
+	for i, bid := range history {
+		if i > 0 && bid.Amount < high+increment.Amount {
+			t.Errorf("bid %d of %d sats accepted over a high bid of %d sats", i, bid.Amount, high)
+		}
+		if bid.Amount > high {
+			high = bid.Amount
+		}
+	}
+
+	// A single bid tops the auction and is its current bid
+	var top []models.SignedBid
+	for _, bid := range history {
+		if bid.Amount == high {
+			top = append(top, bid)
+		}
+	}
+	if len(top) != 1 {
+		t.Fatalf("got %d top bids of %d sats, want 1", len(top), high)
+	}
+
+	final, err := auctionRepo.GetByID(auction.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.CurrentBid == nil || *final.CurrentBid != top[0].Amount {
+		t.Errorf("current bid is %v, want %d", final.CurrentBid, top[0].Amount)
+	}
+	if final.CurrentBidderID == nil || *final.CurrentBidderID != top[0].BidderID {
+		t.Errorf("current bidder is %v, want %s", final.CurrentBidderID, top[0].BidderID)
+	}
+}
+
+func TestResolveBid(t *testing.T) {
+	increment := models.BidIncrement{Type: models.BidIncrementFixed, Amount: 1_000}
+	auctionService := &AuctionService{cfg: config.AuctionConfig{BidIncrement: increment}}
+
+	amount := func(sats int64) *int64 { return &sats }
+
+	type leader struct {
+		bid      int64
+		maxBid   *int64
+		bidderID string
+	}
+	type recorded struct {
+		bidderID  string
+		amount    int64
+		automatic bool
+	}
+
+	tests := []struct {
+		name     string
+		reserve  *int64
+		leader   *leader
+		bidderID string
+		amount   int64
+		maxBid   *int64
+		want     []recorded
+		current  int64
+		winnerID string
+		proxyMax *int64
+	}{
+		{
+			name:     "first bid with a proxy",
+			bidderID: "bidder-1", amount: 10_000, maxBid: amount(50_000),
+			want:    []recorded{{"bidder-1", 10_000, false}},
+			current: 10_000, winnerID: "bidder-1", proxyMax: amount(50_000),
+		},
+		{
+			name:     "proxy outbid by a higher proxy",
+			leader:   &leader{bid: 10_000, maxBid: amount(50_000), bidderID: "bidder-1"},
+			bidderID: "bidder-2", amount: 11_000, maxBid: amount(80_000),
+			want:    []recorded{{"bidder-1", 50_000, true}, {"bidder-2", 51_000, false}},
+			current: 51_000, winnerID: "bidder-2", proxyMax: amount(80_000),
+		},
+		{
+			name:     "proxy defends against a lower proxy",
+			leader:   &leader{bid: 10_000, maxBid: amount(80_000), bidderID: "bidder-1"},
+			bidderID: "bidder-2", amount: 11_000, maxBid: amount(50_000),
+			want:    []recorded{{"bidder-2", 50_000, false}, {"bidder-1", 51_000, true}},
+			current: 51_000, winnerID: "bidder-1", proxyMax: amount(80_000),
+		},
+		{
+			name:     "tie won by the earlier bidder",
+			leader:   &leader{bid: 10_000, maxBid: amount(50_000), bidderID: "bidder-1"},
+			bidderID: "bidder-2", amount: 11_000, maxBid: amount(50_000),
+			want:    []recorded{{"bidder-2", 50_000, false}, {"bidder-1", 50_000, true}},
+			current: 50_000, winnerID: "bidder-1", proxyMax: amount(50_000),
+		},
+		{
+			name:     "plain bid loses to a proxy",
+			leader:   &leader{bid: 10_000, maxBid: amount(50_000), bidderID: "bidder-1"},
+			bidderID: "bidder-2", amount: 20_000,
+			want:    []recorded{{"bidder-2", 20_000, false}, {"bidder-1", 21_000, true}},
+			current: 21_000, winnerID: "bidder-1", proxyMax: amount(50_000),
+		},
+		{
+			name:     "plain bid outbids a plain bid",
+			leader:   &leader{bid: 10_000, bidderID: "bidder-1"},
+			bidderID: "bidder-2", amount: 11_000,
+			want:    []recorded{{"bidder-2", 11_000, false}},
+			current: 11_000, winnerID: "bidder-2",
+		},
+		{
+			name:     "leader raises their own maximum",
+			leader:   &leader{bid: 10_000, maxBid: amount(50_000), bidderID: "bidder-1"},
+			bidderID: "bidder-1", amount: 11_000, maxBid: amount(90_000),
+			want:    []recorded{{"bidder-1", 11_000, false}},
+			current: 11_000, winnerID: "bidder-1", proxyMax: amount(90_000),
+		},
+		{
+			name:     "leader keeps their higher maximum",
+			leader:   &leader{bid: 10_000, maxBid: amount(50_000), bidderID: "bidder-1"},
+			bidderID: "bidder-1", amount: 11_000, maxBid: amount(30_000),
+			want:    []recorded{{"bidder-1", 11_000, false}},
+			current: 11_000, winnerID: "bidder-1", proxyMax: amount(50_000),
+		},
+		{
+			name:     "reserve raises the first bid",
+			reserve:  amount(40_000),
+			bidderID: "bidder-1", amount: 10_000, maxBid: amount(60_000),
+			want:    []recorded{{"bidder-1", 40_000, false}},
+			current: 40_000, winnerID: "bidder-1", proxyMax: amount(60_000),
+		},
+		{
+			name:     "reserve above the maximum",
+			reserve:  amount(40_000),
+			bidderID: "bidder-1", amount: 10_000, maxBid: amount(30_000),
+			want:    []recorded{{"bidder-1", 30_000, false}},
+			current: 30_000, winnerID: "bidder-1",
+		},
+		{
+			name:     "reserve raises a proxy outbidding a proxy",
+			reserve:  amount(60_000),
+			leader:   &leader{bid: 10_000, maxBid: amount(50_000), bidderID: "bidder-1"},
+			bidderID: "bidder-2", amount: 11_000, maxBid: amount(80_000),
+			want:    []recorded{{"bidder-1", 50_000, true}, {"bidder-2", 60_000, false}},
+			current: 60_000, winnerID: "bidder-2", proxyMax: amount(80_000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &models.Auction{
+				ID:           "auction",
+				Type:         models.AuctionTypeEnglish,
+				StartPrice:   10_000,
+				ReservePrice: tt.reserve,
+			}
+			if tt.leader != nil {
+				auction.CurrentBid = &tt.leader.bid
+				auction.CurrentBidderID = &tt.leader.bidderID
+				if tt.leader.maxBid != nil {
+					wallet := "wallet-" + tt.leader.bidderID
+					auction.ProxyMaxBid = tt.leader.maxBid
+					auction.ProxyWalletID = &wallet
+				}
+			}
+
+			bid := &models.Bid{
+				AuctionID: auction.ID,
+				BidderID:  tt.bidderID,
+				WalletID:  "wallet-" + tt.bidderID,
+				Amount:    tt.amount,
+				MaxAmount: tt.maxBid,
+			}
+
+			bids := auctionService.resolveBid(auction, bid)
+			if len(bids) != len(tt.want) {
+				t.Fatalf("got %d bids, want %d", len(bids), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := bids[i]
+				if got.BidderID != want.bidderID || got.Amount != want.amount || got.Automatic != want.automatic {
+					t.Errorf("bid %d: got %s at %d sats (automatic %t), want %s at %d sats (automatic %t)",
+						i, got.BidderID, got.Amount, got.Automatic, want.bidderID, want.amount, want.automatic)
+				}
+				if got.WalletID != "wallet-"+want.bidderID {
+					t.Errorf("bid %d: got wallet %s, want wallet-%s", i, got.WalletID, want.bidderID)
+				}
+			}
+
+			if auction.CurrentBid == nil || *auction.CurrentBid != tt.current {
+				t.Errorf("got current bid %v, want %d", auction.CurrentBid, tt.current)
+			}
+			if auction.CurrentBidderID == nil || *auction.CurrentBidderID != tt.winnerID {
+				t.Errorf("got current bidder %v, want %s", auction.CurrentBidderID, tt.winnerID)
+			}
+			switch {
+			case tt.proxyMax == nil && auction.ProxyMaxBid != nil:
+				t.Errorf("got proxy maximum %d, want none", *auction.ProxyMaxBid)
+			case tt.proxyMax != nil && (auction.ProxyMaxBid == nil || *auction.ProxyMaxBid != *tt.proxyMax):
+				t.Errorf("got proxy maximum %v, want %d", auction.ProxyMaxBid, *tt.proxyMax)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -34,10 +36,78 @@ func NewAuthService(userRepo *store.UserRepository, emailService *EmailService,
 	}
 }
 
-// AuthenticateWithWallet authenticates a user with a wallet signature
-func (s *AuthService) AuthenticateWithWallet(req models.WalletAuthRequest) (*models.AuthToken, error) {
+// CreateChallenge issues a login challenge for a wallet address
+func (s *AuthService) CreateChallenge(req models.ChallengeRequest) (*models.AuthChallenge, error) {
+	// Validate address
+	if !s.walletService.IsAddressValid(req.Address) {
+		return nil, fmt.Errorf("invalid wallet address")
+	}
+
+	// Generate a random nonce
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return nil, err
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+
+	// Set validity window
+	expiration := s.cfg.ChallengeExpiration
+	if expiration <= 0 {
+		expiration = 5 // Default expiry time
+	}
+	issuedAt := time.Now().UTC().Truncate(time.Second)
+	expiresAt := issuedAt.Add(time.Duration(expiration) * time.Minute)
+
+	challenge := &models.AuthChallenge{
+		Address:   req.Address,
+		Nonce:     nonce,
+		Message:   s.walletService.GenerateMessageToSign(s.cfg.Domain, req.Address, nonce, issuedAt, expiresAt),
+		IssuedAt:  issuedAt,
+		ExpiresAt: expiresAt,
+	}
+
+	// Store the challenge
+	if err := s.userRepo.CreateChallenge(challenge); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// verifyChallengeSignature checks that a wallet signed an unexpired, unused
+// challenge and consumes the challenge so it cannot be replayed
+func (s *AuthService) verifyChallengeSignature(req models.WalletAuthRequest) error {
+	challenge, err := s.checkChallengeSignature(req)
+	if err != nil {
+		return err
+	}
+
+	return s.consumeChallenge(challenge)
+}
+
+// checkChallengeSignature checks that a wallet signed an unexpired, unused
+// challenge without consuming it
+func (s *AuthService) checkChallengeSignature(req models.WalletAuthRequest) (*models.AuthChallenge, error) {
+	// Find the challenge that was issued for this address
+	challenge, err := s.userRepo.GetChallenge(req.Address, req.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge == nil {
+		return nil, fmt.Errorf("challenge not found")
+	}
+
+	if challenge.UsedAt != nil {
+		return nil, fmt.Errorf("challenge already used")
+	}
+
+	if time.Now().After(challenge.ExpiresAt) {
+		return nil, fmt.Errorf("challenge expired")
+	}
+
 	// Verify the signature
-	valid, err := s.walletService.VerifySignature(req.Address, req.Message, req.Signature)
+	valid, err := s.walletService.VerifySignature(req.Address, challenge.Message, req.Signature)
 	if err != nil {
 		return nil, fmt.Errorf("signature verification failed: %w", err)
 	}
@@ -46,6 +116,31 @@ func (s *AuthService) AuthenticateWithWallet(req models.WalletAuthRequest) (*mod
 		return nil, fmt.Errorf("invalid signature")
 	}
 
+	return challenge, nil
+}
+
+// consumeChallenge marks a challenge as used, failing if a concurrent request
+// already did
+func (s *AuthService) consumeChallenge(challenge *models.AuthChallenge) error {
+	consumed, err := s.userRepo.ConsumeChallenge(challenge.ID)
+	if err != nil {
+		return err
+	}
+
+	if !consumed {
+		return fmt.Errorf("challenge already used")
+	}
+
+	return nil
+}
+
+// AuthenticateWithWallet authenticates a user with a signed login challenge
+func (s *AuthService) AuthenticateWithWallet(req models.WalletAuthRequest) (*models.AuthToken, error) {
+	// Verify the signed challenge
+	if err := s.verifyChallengeSignature(req); err != nil {
+		return nil, err
+	}
+
 	// Find or create user based on wallet address
 	user, err := s.userRepo.GetByWalletAddress(req.Address)
 	if err != nil {
@@ -60,7 +155,12 @@ func (s *AuthService) AuthenticateWithWallet(req models.WalletAuthRequest) (*mod
 		}
 
 		// Add the wallet to the user
-		_, err = s.userRepo.AddWallet(user.ID, req.Address, "bitcoin")
+		scriptType, err := s.walletService.AddressType(req.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = s.userRepo.AddWallet(user.ID, req.Address, "bitcoin", scriptType)
 		if err != nil {
 			return nil, err
 		}
@@ -210,16 +310,13 @@ func (s *AuthService) VerifyEmailCode(req models.EmailVerifyRequest) (*models.Au
 	}, nil
 }
 
-// LinkWallet links a wallet to an existing user
+// LinkWallet links a wallet to an existing user. The challenge is only
+// consumed once the wallet is known to be free to link.
 func (s *AuthService) LinkWallet(userID string, req models.WalletAuthRequest) error {
-	// Verify the signature
-	valid, err := s.walletService.VerifySignature(req.Address, req.Message, req.Signature)
+	// Verify the signed challenge
+	challenge, err := s.checkChallengeSignature(req)
 	if err != nil {
-		return fmt.Errorf("signature verification failed: %w", err)
-	}
-
-	if !valid {
-		return fmt.Errorf("invalid signature")
+		return err
 	}
 
 	// Check if wallet already exists
@@ -232,8 +329,17 @@ func (s *AuthService) LinkWallet(userID string, req models.WalletAuthRequest) er
 		return fmt.Errorf("wallet already linked to another user")
 	}
 
+	scriptType, err := s.walletService.AddressType(req.Address)
+	if err != nil {
+		return err
+	}
+
+	if err := s.consumeChallenge(challenge); err != nil {
+		return err
+	}
+
 	// Add the wallet to the user
-	_, err = s.userRepo.AddWallet(userID, req.Address, "bitcoin")
+	_, err = s.userRepo.AddWallet(userID, req.Address, "bitcoin", scriptType)
 	return err
 }
 
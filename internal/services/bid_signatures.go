@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// Bids are signed by the bidding wallet so that anyone can check an auction's
+// bid history against the bidders' addresses. The signed message names the
+// auction, the amount and any proxy maximum, the wallet address, a client
+// nonce and a timestamp, and is stored with the bid; each message can only be
+// used once. Automatic bids are placed by the engine within a signed maximum
+// and sealed bids are bound by their commitment, so neither carries a
+// signature.
+
+// bidSignatureWindow is how far the timestamp of a signed bid may be from the
+// server time
+const bidSignatureWindow = 5 * time.Minute
+
+// bidNoncePattern restricts nonces to characters that cannot alter the layout
+// of the signed message
+var bidNoncePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+// verifyBidSignature checks that a wallet signed a bid of amount, up to an
+// optional proxy maximum, on an auction and returns the signed message
+func (s *AuctionService) verifyBidSignature(wallet *models.Wallet, auctionID string, amount int64, maxAmount *int64, sig models.BidSignature) (string, error) {
+	if sig.Signature == "" {
+		return "", fmt.Errorf("bid must be signed by the bidding wallet")
+	}
+
+	if !bidNoncePattern.MatchString(sig.Nonce) {
+		return "", fmt.Errorf("bid nonce must be 8 to 64 letters, digits, dashes or underscores")
+	}
+
+	if age := time.Since(sig.Timestamp); age > bidSignatureWindow || age < -bidSignatureWindow {
+		return "", fmt.Errorf("bid timestamp must be within %d minutes of the server time", int(bidSignatureWindow.Minutes()))
+	}
+
+	message := s.walletService.GenerateBidMessage(auctionID, wallet.Address, sig.Nonce, amount, maxAmount, sig.Timestamp)
+	valid, err := s.walletService.VerifySignature(wallet.Address, message, sig.Signature)
+	if err != nil {
+		return "", fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	if !valid {
+		return "", fmt.Errorf("invalid bid signature")
+	}
+
+	return message, nil
+}
+
+// GetBidHistory exports the bids of an auction in the order they were placed,
+// with the signed message and signature of each. Proxy bids are only signed
+// along with their hidden maximum, so their message and signature are never
+// exported. It returns nil if the auction does not exist.
+func (s *AuctionService) GetBidHistory(auctionID string) (*models.BidHistoryResponse, error) {
+	auction, err := s.auctionRepo.GetByID(auctionID)
+	if err != nil || auction == nil {
+		return nil, err
+	}
+
+	bids, err := s.auctionRepo.GetSignedBids(auctionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range bids {
+		if bids[i].MaxAmount != nil {
+			bids[i].Message = nil
+			bids[i].Signature = nil
+		}
+	}
+
+	return &models.BidHistoryResponse{
+		AuctionID: auctionID,
+		Bids:      bids,
+	}, nil
+}
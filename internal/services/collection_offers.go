@@ -0,0 +1,233 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// Collection offers cannot be signed up front since the inscription is only
+// known once a holder fills them. Each fill takes an item and creates a
+// pending Offer on the holder's NFT; the offerer signs it like any other
+// offer, after which it is already accepted and the holder settles it. Fills
+// that lapse hand their item back.
+
+// GetCollectionOffer retrieves a collection offer by ID
+func (s *OfferService) GetCollectionOffer(id string) (*models.CollectionOffer, error) {
+	return s.offerRepo.GetCollectionOfferByID(id)
+}
+
+// ListCollectionOffers retrieves collection offers with filtering and pagination
+func (s *OfferService) ListCollectionOffers(params models.CollectionOfferParams) (*models.CollectionOfferListResponse, error) {
+	collectionOffers, total, err := s.offerRepo.ListCollectionOffers(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CollectionOfferListResponse{
+		CollectionOffers: collectionOffers,
+		TotalCount:       total,
+		Page:             params.Page,
+		PageSize:         params.PageSize,
+	}, nil
+}
+
+// CreateCollectionOffer makes an offer for a quantity of any NFTs of a
+// collection. The funding wallet must cover every item.
+func (s *OfferService) CreateCollectionOffer(req models.CreateCollectionOfferRequest, userID string) (*models.CollectionOffer, error) {
+	if req.Collection == "" {
+		return nil, fmt.Errorf("collection is required")
+	}
+
+	if req.Price < dustLimit {
+		return nil, fmt.Errorf("price must be at least %d sats", dustLimit)
+	}
+
+	if req.Quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+
+	if !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expiry must be in the future")
+	}
+
+	// Verify wallet belongs to user
+	wallet, err := s.userWallet(userID, req.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	receiveWallet := wallet
+	if req.ReceiveAddress != "" && req.ReceiveAddress != wallet.Address {
+		receiveWallet, err = s.userWalletByAddress(userID, req.ReceiveAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Check that the wallet can settle every item
+	inscribed, err := s.nftRepo.GetInscribedOutpoints(wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	funds, err := s.walletService.SettlementFunds(wallet.Address, inscribed)
+	if err != nil {
+		return nil, err
+	}
+
+	if funds < req.Price*int64(req.Quantity) {
+		return nil, fmt.Errorf("insufficient funds: the wallet can settle %d sats after fees", funds)
+	}
+
+	collectionOffer := &models.CollectionOffer{
+		Collection:      req.Collection,
+		OffererID:       userID,
+		WalletID:        wallet.ID,
+		ReceiveWalletID: receiveWallet.ID,
+		Price:           req.Price,
+		Quantity:        req.Quantity,
+		Status:          models.CollectionOfferStatusOpen,
+		ExpiresAt:       req.ExpiresAt,
+	}
+
+	if err := s.offerRepo.CreateCollectionOffer(collectionOffer); err != nil {
+		return nil, err
+	}
+
+	return collectionOffer, nil
+}
+
+// FillCollectionOffer sells one of the user's NFTs into a collection offer. It
+// returns the pending offer for the NFT, which the offerer signs to accept. An
+// NFT fills a collection offer once until that fill lapses.
+func (s *OfferService) FillCollectionOffer(req models.FillCollectionOfferRequest, userID string) (*models.Offer, error) {
+	collectionOffer, err := s.offerRepo.GetCollectionOfferByID(req.CollectionOfferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if collectionOffer == nil {
+		return nil, fmt.Errorf("collection offer not found")
+	}
+
+	if collectionOffer.OffererID == userID {
+		return nil, fmt.Errorf("you cannot fill your own collection offer")
+	}
+
+	// Get the NFT
+	nft, err := s.nftRepo.GetByID(req.NFTID)
+	if err != nil {
+		return nil, err
+	}
+
+	if nft == nil {
+		return nil, fmt.Errorf("NFT not found")
+	}
+
+	ownerWallet, err := s.userWallet(userID, nft.WalletID)
+	if err != nil {
+		return nil, fmt.Errorf("you don't own this NFT")
+	}
+
+	if nft.Collection != collectionOffer.Collection {
+		return nil, fmt.Errorf("NFT is not part of collection %s", collectionOffer.Collection)
+	}
+
+	if nft.AuctionID != nil {
+		return nil, fmt.Errorf("NFT is on auction")
+	}
+
+	if nft.Location == "" {
+		return nil, fmt.Errorf("NFT location is unknown")
+	}
+
+	wallet, err := s.userRepo.GetWalletByID(collectionOffer.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	receiveWallet, err := s.userRepo.GetWalletByID(collectionOffer.ReceiveWalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil || receiveWallet == nil {
+		return nil, fmt.Errorf("offerer wallet not found")
+	}
+
+	// Build the offer PSBT for the offerer to sign, paying the fees out of
+	// the price
+	fees, err := s.fees.settle(collectionOffer.Price, 0, []models.NFT{*nft})
+	if err != nil {
+		return nil, err
+	}
+
+	offerPSBT, err := s.walletService.BuildOfferPSBT(OfferPSBTParams{
+		Location:       nft.Location,
+		OwnerAddress:   ownerWallet.Address,
+		BuyerAddress:   wallet.Address,
+		ReceiveAddress: receiveWallet.Address,
+		Amount:         collectionOffer.Price,
+		Payouts:        s.fees.payouts(fees),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	offer := &models.Offer{
+		NFTID:             nft.ID,
+		OffererID:         collectionOffer.OffererID,
+		WalletID:          wallet.ID,
+		ReceiveWalletID:   receiveWallet.ID,
+		OwnerWalletID:     ownerWallet.ID,
+		CollectionOfferID: &collectionOffer.ID,
+		Amount:            collectionOffer.Price,
+		Location:          nft.Location,
+		PSBT:              offerPSBT.PSBT,
+		Status:            models.OfferStatusPending,
+	}
+
+	// Take an item on the locked collection offer
+	err = s.offerRepo.FillCollectionOffer(offer, func(locked *models.CollectionOffer) error {
+		if locked.Status != models.CollectionOfferStatusOpen || locked.Remaining <= 0 {
+			return fmt.Errorf("collection offer is no longer open")
+		}
+
+		if !time.Now().Before(locked.ExpiresAt) {
+			return fmt.Errorf("collection offer has expired")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+// CancelCollectionOffer withdraws one of the user's collection offers. Fills
+// already made stay and can be cancelled one by one.
+func (s *OfferService) CancelCollectionOffer(id, userID string) error {
+	collectionOffer, err := s.offerRepo.GetCollectionOfferByID(id)
+	if err != nil {
+		return err
+	}
+
+	if collectionOffer == nil || collectionOffer.OffererID != userID {
+		return fmt.Errorf("collection offer not found")
+	}
+
+	cancelled, err := s.offerRepo.CancelCollectionOffer(id)
+	if err != nil {
+		return err
+	}
+
+	if !cancelled {
+		return fmt.Errorf("collection offer can no longer be cancelled")
+	}
+
+	return nil
+}
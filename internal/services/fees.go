@@ -0,0 +1,168 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/satonic/satonic-api/internal/config"
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// Every sale pays the platform fee and the royalty of the collection of each
+// NFT it sells, in basis points of the price, out of the seller's proceeds.
+// Listing PSBTs pay the seller the proceeds of the listing price; settlement
+// adds an output for each fee and pays the seller the rest. Fees below the
+// dust limit are waived.
+
+// feeSchedule computes the fees taken from sales
+type feeSchedule struct {
+	cfg config.FeeConfig
+}
+
+// basisPoints returns bps basis points of an amount, rounded down
+func basisPoints(amount int64, bps int) int64 {
+	return amount/10000*int64(bps) + amount%10000*int64(bps)/10000
+}
+
+// split computes the fees of a sale. The royalties of a lot share its price
+// evenly between its NFTs.
+func (f feeSchedule) split(price int64, nfts []models.NFT) *models.FeeBreakdown {
+	breakdown := &models.FeeBreakdown{
+		Price:       price,
+		PlatformFee: basisPoints(price, f.cfg.PlatformBasisPoints),
+	}
+
+	for _, nft := range nfts {
+		royalty, ok := f.cfg.Royalties[nft.Collection]
+		if !ok || royalty.BasisPoints == 0 {
+			continue
+		}
+		amount := basisPoints(price/int64(len(nfts)), royalty.BasisPoints)
+
+		// NFTs of the same collection pay a single royalty
+		merged := false
+		for i := range breakdown.Royalties {
+			if breakdown.Royalties[i].Collection == nft.Collection {
+				breakdown.Royalties[i].Amount += amount
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			breakdown.Royalties = append(breakdown.Royalties, models.Royalty{
+				Collection: nft.Collection,
+				Address:    royalty.Address,
+				Amount:     amount,
+			})
+		}
+	}
+
+	breakdown.SellerProceeds = price - totalFees(breakdown)
+	return breakdown
+}
+
+// listingProceeds returns what a listing PSBT must pay the seller for a
+// listing price
+func (f feeSchedule) listingProceeds(price int64, nfts []models.NFT) int64 {
+	return f.split(price, nfts).SellerProceeds
+}
+
+// settle computes the fees paid out of a sale whose listing PSBT already pays
+// the seller listed sats. When waiving dust leaves the seller less than that,
+// the platform fee gives way.
+func (f feeSchedule) settle(price, listed int64, nfts []models.NFT) (*models.FeeBreakdown, error) {
+	breakdown := f.split(price, nfts)
+
+	if breakdown.PlatformFee < dustLimit {
+		breakdown.PlatformFee = 0
+	}
+
+	var royalties []models.Royalty
+	for _, royalty := range breakdown.Royalties {
+		if royalty.Amount >= dustLimit {
+			royalties = append(royalties, royalty)
+		}
+	}
+	breakdown.Royalties = royalties
+	breakdown.SellerProceeds = price - totalFees(breakdown)
+
+	if short := listed - breakdown.SellerProceeds; short > 0 {
+		breakdown.PlatformFee -= short
+		if breakdown.PlatformFee < dustLimit {
+			breakdown.PlatformFee = 0
+		}
+		breakdown.SellerProceeds = price - totalFees(breakdown)
+	}
+
+	if breakdown.SellerProceeds < listed {
+		return nil, fmt.Errorf("price %d does not cover the listing and its fees", price)
+	}
+
+	return breakdown, nil
+}
+
+// payouts returns the outputs paying the fees of a sale
+func (f feeSchedule) payouts(breakdown *models.FeeBreakdown) []Payout {
+	var payouts []Payout
+	if breakdown.PlatformFee > 0 {
+		payouts = append(payouts, Payout{Address: f.cfg.PlatformAddress, Amount: breakdown.PlatformFee})
+	}
+
+	for _, royalty := range breakdown.Royalties {
+		payouts = append(payouts, Payout{Address: royalty.Address, Amount: royalty.Amount})
+	}
+
+	return payouts
+}
+
+// totalFees returns the sum of the fees of a sale
+func totalFees(breakdown *models.FeeBreakdown) int64 {
+	total := breakdown.PlatformFee
+	for _, royalty := range breakdown.Royalties {
+		total += royalty.Amount
+	}
+
+	return total
+}
+
+// saleNFTs returns the NFTs sold with an NFT, which is the first of its lot
+// if it has one
+func saleNFTs(nft *models.NFT, lot []models.NFT) []models.NFT {
+	if len(lot) > 0 {
+		return lot
+	}
+
+	if nft != nil {
+		return []models.NFT{*nft}
+	}
+
+	return nil
+}
+
+// auctionListingPrice returns the price an auction's listing PSBT was made for
+func auctionListingPrice(auction *models.Auction) int64 {
+	if auction.Type == models.AuctionTypeDutch && auction.FloorPrice != nil {
+		return *auction.FloorPrice
+	}
+
+	return auction.StartPrice
+}
+
+// settlementFees computes the fees paid out of the winning bid of an auction,
+// on top of what its listing PSBT pays the seller
+func (s *AuctionService) settlementFees(auction *models.Auction) (*models.FeeBreakdown, error) {
+	if auction.NFT == nil || auction.NFT.Location == "" {
+		return nil, fmt.Errorf("NFT location is unknown")
+	}
+
+	lot, err := lotLocations(auction.Lot)
+	if err != nil {
+		return nil, err
+	}
+
+	listed, err := s.walletService.ListingProceeds(auction.PSBT, append([]string{auction.NFT.Location}, lot...))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.fees.settle(*auction.CurrentBid, listed, saleNFTs(auction.NFT, auction.Lot))
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/satonic/satonic-api/internal/config"
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// testFees charges a 2% platform fee and royalties on two collections
+var testFees = feeSchedule{cfg: config.FeeConfig{
+	PlatformAddress:     "platform",
+	PlatformBasisPoints: 200,
+	Royalties: map[string]config.RoyaltyConfig{
+		"punks": {Address: "punks-creator", BasisPoints: 500},
+		"apes":  {Address: "apes-creator", BasisPoints: 300},
+		"free":  {Address: "free-creator"},
+	},
+}}
+
+// testNFTs returns an NFT of each collection, in order
+func testNFTs(collections ...string) []models.NFT {
+	nfts := make([]models.NFT, len(collections))
+	for i, collection := range collections {
+		nfts[i] = models.NFT{Collection: collection}
+	}
+	return nfts
+}
+
+func TestBasisPoints(t *testing.T) {
+	tests := []struct {
+		amount int64
+		bps    int
+		want   int64
+	}{
+		{amount: 10_000, bps: 250, want: 250},
+		{amount: 9_999, bps: 250, want: 249},
+		{amount: 123_456_789, bps: 175, want: 2_160_493},
+		{amount: 1, bps: 9_999, want: 0},
+		{amount: 50_000, bps: 0, want: 0},
+		// The whole supply in sats would overflow amount*bps
+		{amount: 2_100_000_000_000_000, bps: 250, want: 52_500_000_000_000},
+	}
+
+	for _, tt := range tests {
+		if got := basisPoints(tt.amount, tt.bps); got != tt.want {
+			t.Errorf("basisPoints(%d, %d): got %d, want %d", tt.amount, tt.bps, got, tt.want)
+		}
+	}
+}
+
+func TestFeeScheduleSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		price int64
+		nfts  []models.NFT
+		want  *models.FeeBreakdown
+	}{
+		{
+			name:  "single NFT",
+			price: 100_000,
+			nfts:  testNFTs("punks"),
+			want: &models.FeeBreakdown{
+				Price:          100_000,
+				PlatformFee:    2_000,
+				Royalties:      []models.Royalty{{Collection: "punks", Address: "punks-creator", Amount: 5_000}},
+				SellerProceeds: 93_000,
+			},
+		},
+		{
+			name:  "lot royalties merged by collection",
+			price: 90_001,
+			nfts:  testNFTs("punks", "apes", "punks"),
+			want: &models.FeeBreakdown{
+				Price:       90_001,
+				PlatformFee: 1_800,
+				Royalties: []models.Royalty{
+					{Collection: "punks", Address: "punks-creator", Amount: 3_000},
+					{Collection: "apes", Address: "apes-creator", Amount: 900},
+				},
+				SellerProceeds: 84_301,
+			},
+		},
+		{
+			name:  "collections without royalties",
+			price: 100_000,
+			nfts:  testNFTs("free", "unknown"),
+			want: &models.FeeBreakdown{
+				Price:          100_000,
+				PlatformFee:    2_000,
+				SellerProceeds: 98_000,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testFees.split(tt.price, tt.nfts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+
+			if proceeds := testFees.listingProceeds(tt.price, tt.nfts); proceeds != tt.want.SellerProceeds {
+				t.Errorf("got listing proceeds %d, want %d", proceeds, tt.want.SellerProceeds)
+			}
+		})
+	}
+}
+
+func TestFeeScheduleSettle(t *testing.T) {
+	punks := models.Royalty{Collection: "punks", Address: "punks-creator", Amount: 5_000}
+
+	tests := []struct {
+		name    string
+		price   int64
+		listed  int64
+		nfts    []models.NFT
+		want    *models.FeeBreakdown
+		payouts []Payout
+		wantErr bool
+	}{
+		{
+			name:   "listing at a lower start price",
+			price:  100_000,
+			listed: testFees.listingProceeds(50_000, testNFTs("punks")),
+			nfts:   testNFTs("punks"),
+			want: &models.FeeBreakdown{
+				Price:          100_000,
+				PlatformFee:    2_000,
+				Royalties:      []models.Royalty{punks},
+				SellerProceeds: 93_000,
+			},
+			payouts: []Payout{{Address: "platform", Amount: 2_000}, {Address: "punks-creator", Amount: 5_000}},
+		},
+		{
+			name:   "dust platform fee waived",
+			price:  20_000,
+			listed: 10_000,
+			nfts:   testNFTs("punks"),
+			want: &models.FeeBreakdown{
+				Price:          20_000,
+				Royalties:      []models.Royalty{{Collection: "punks", Address: "punks-creator", Amount: 1_000}},
+				SellerProceeds: 19_000,
+			},
+			payouts: []Payout{{Address: "punks-creator", Amount: 1_000}},
+		},
+		{
+			name:   "every dust fee waived",
+			price:  10_000,
+			listed: 9_000,
+			nfts:   testNFTs("apes"),
+			want: &models.FeeBreakdown{
+				Price:          10_000,
+				SellerProceeds: 10_000,
+			},
+		},
+		{
+			name:   "merged lot royalty above dust",
+			price:  12_000,
+			listed: 10_000,
+			nfts:   testNFTs("punks", "punks"),
+			want: &models.FeeBreakdown{
+				Price:          12_000,
+				Royalties:      []models.Royalty{{Collection: "punks", Address: "punks-creator", Amount: 600}},
+				SellerProceeds: 11_400,
+			},
+			payouts: []Payout{{Address: "punks-creator", Amount: 600}},
+		},
+		{
+			name:   "platform fee reduced for the listing",
+			price:  100_000,
+			listed: 94_000,
+			nfts:   testNFTs("punks"),
+			want: &models.FeeBreakdown{
+				Price:          100_000,
+				PlatformFee:    1_000,
+				Royalties:      []models.Royalty{punks},
+				SellerProceeds: 94_000,
+			},
+			payouts: []Payout{{Address: "platform", Amount: 1_000}, {Address: "punks-creator", Amount: 5_000}},
+		},
+		{
+			name:   "platform fee reduced below dust",
+			price:  100_000,
+			listed: 94_600,
+			nfts:   testNFTs("punks"),
+			want: &models.FeeBreakdown{
+				Price:          100_000,
+				Royalties:      []models.Royalty{punks},
+				SellerProceeds: 95_000,
+			},
+			payouts: []Payout{{Address: "punks-creator", Amount: 5_000}},
+		},
+		{
+			name:    "price below the listing and royalties",
+			price:   100_000,
+			listed:  96_000,
+			nfts:    testNFTs("punks"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testFees.settle(tt.price, tt.listed, tt.nfts)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if payouts := testFees.payouts(got); !reflect.DeepEqual(payouts, tt.payouts) {
+				t.Errorf("got payouts %+v, want %+v", payouts, tt.payouts)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// Fixed-price listings are auctions of type fixed_price: they lock the NFT
+// through its auction_id, are bought outright at their start price through
+// BuyNow and settle like any other auction.
+
+// CreateListing lists an NFT at a fixed price
+func (s *AuctionService) CreateListing(req models.CreateListingRequest, userID string) (*models.Auction, error) {
+	endTime := models.ListingNoExpiry
+	if req.ExpiresAt != nil {
+		endTime = *req.ExpiresAt
+	}
+
+	return s.Create(models.CreateAuctionRequest{
+		Type:       models.AuctionTypeFixedPrice,
+		NFTID:      req.NFTID,
+		StartPrice: req.Price,
+		StartTime:  time.Now(),
+		EndTime:    endTime,
+		PSBT:       req.PSBT,
+	}, userID)
+}
+
+// validateListing checks a fixed-price listing request. Auction settings are
+// rejected.
+func validateListing(req *models.CreateAuctionRequest) error {
+	if req.StartPrice <= 0 {
+		return fmt.Errorf("price must be positive")
+	}
+
+	if req.EndTime.IsZero() {
+		req.EndTime = models.ListingNoExpiry
+	}
+
+	if !req.EndTime.After(time.Now()) {
+		return fmt.Errorf("expiry must be in the future")
+	}
+
+	if req.ReservePrice != nil || req.BuyNowPrice != nil || req.ExtensionWindow != 0 ||
+		req.MaxExtensions != nil || req.BidIncrement != nil {
+		return fmt.Errorf("fixed-price listings do not support reserve, buy now, soft close or bid increment settings")
+	}
+
+	return nil
+}
+
+// getSellerListing retrieves a fixed-price listing owned by the user
+func (s *AuctionService) getSellerListing(listingID, userID string) (*models.Auction, *models.NFT, *models.Wallet, error) {
+	listing, err := s.auctionRepo.GetByID(listingID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if listing == nil || listing.Type != models.AuctionTypeFixedPrice {
+		return nil, nil, nil, fmt.Errorf("listing not found")
+	}
+
+	sellerWallet, err := s.bidderWallet(userID, listing.SellerWalletID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("only the seller can change the listing")
+	}
+
+	nft, err := s.nftRepo.GetByID(listing.NFTID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if nft == nil {
+		return nil, nil, nil, fmt.Errorf("NFT not found")
+	}
+
+	return listing, nft, sellerWallet, nil
+}
+
+// RepriceListing changes the price of an active listing. The seller signs a
+// new listing PSBT paying the new price.
+func (s *AuctionService) RepriceListing(req models.RepriceListingRequest, userID string) (*models.Auction, error) {
+	if req.Price <= 0 {
+		return nil, fmt.Errorf("price must be positive")
+	}
+
+	_, nft, sellerWallet, err := s.getSellerListing(req.ListingID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	lot, err := s.auctionRepo.GetLot(req.ListingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the new PSBT
+	err = s.walletService.ValidatePSBT(req.PSBT, ListingPSBTParams{
+		InscriptionID: nft.InscriptionID,
+		Location:      nft.Location,
+		SellerAddress: sellerWallet.Address,
+		Price:         s.fees.listingProceeds(req.Price, saleNFTs(nft, lot)),
+		Lot:           lotInscriptions(lot),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repriced, err := s.auctionRepo.Reprice(req.ListingID, req.Price, req.PSBT)
+	if err != nil {
+		return nil, err
+	}
+
+	if !repriced {
+		return nil, fmt.Errorf("listing is no longer active")
+	}
+
+	if s.notifier != nil {
+		s.notifier.NotifyAuction(req.ListingID, "price_update", models.AuctionPriceUpdate{
+			AuctionID:    req.ListingID,
+			CurrentPrice: req.Price,
+		})
+	}
+
+	return s.GetByID(req.ListingID)
+}
+
+// Delist takes an active listing down and releases its NFT
+func (s *AuctionService) Delist(listingID, userID string) error {
+	if _, _, _, err := s.getSellerListing(listingID, userID); err != nil {
+		return err
+	}
+
+	cancelled, err := s.auctionRepo.CancelAuction(listingID, models.AuctionStatusActive)
+	if err != nil {
+		return err
+	}
+
+	if !cancelled {
+		return fmt.Errorf("listing is no longer active")
+	}
+
+	s.notifyStatus(models.AuctionStatusUpdate{
+		AuctionID: listingID,
+		Status:    models.AuctionStatusCancelled,
+		Reason:    "delisted by the seller",
+	})
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// Lot auctions sell several NFTs of one seller wallet together. The auction's
+// NFTID is the first NFT of the lot, every NFT is locked through its
+// auction_id and the listing PSBT spends each inscription with its own
+// SIGHASH_SINGLE|ANYONECANPAY input. Settlement delivers all of them to the
+// winner's first output in one transaction.
+
+// getLotNFTs retrieves the NFTs of a new lot auction, which must all be held
+// by the seller's wallet and free to auction
+func (s *AuctionService) getLotNFTs(nftIDs []string, sellerWalletID string) ([]models.NFT, error) {
+	lot := make([]models.NFT, 0, len(nftIDs))
+	seen := make(map[string]bool)
+	for _, nftID := range nftIDs {
+		if seen[nftID] {
+			return nil, fmt.Errorf("NFT %s is listed twice", nftID)
+		}
+		seen[nftID] = true
+
+		nft, err := s.nftRepo.GetByID(nftID)
+		if err != nil {
+			return nil, err
+		}
+
+		if nft == nil {
+			return nil, fmt.Errorf("NFT %s not found", nftID)
+		}
+
+		if nft.WalletID != sellerWalletID {
+			return nil, fmt.Errorf("every NFT of a lot must be held by the same wallet")
+		}
+
+		if nft.AuctionID != nil {
+			return nil, fmt.Errorf("NFT %s is already on auction", nftID)
+		}
+
+		if nft.Location == "" {
+			return nil, fmt.Errorf("location of NFT %s is unknown", nftID)
+		}
+
+		lot = append(lot, *nft)
+	}
+
+	return lot, nil
+}
+
+// lotInscriptions returns the inscriptions a lot's listing PSBT sells after
+// the first one
+func lotInscriptions(lot []models.NFT) []ListedInscription {
+	var inscriptions []ListedInscription
+	for i := 1; i < len(lot); i++ {
+		inscriptions = append(inscriptions, ListedInscription{
+			InscriptionID: lot[i].InscriptionID,
+			Location:      lot[i].Location,
+		})
+	}
+
+	return inscriptions
+}
+
+// lotLocations returns the satpoints of the inscriptions of a lot after the
+// first one
+func lotLocations(lot []models.NFT) ([]string, error) {
+	var locations []string
+	for i := 1; i < len(lot); i++ {
+		if lot[i].Location == "" {
+			return nil, fmt.Errorf("location of NFT %s is unknown", lot[i].ID)
+		}
+		locations = append(locations, lot[i].Location)
+	}
+
+	return locations, nil
+}
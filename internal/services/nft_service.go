@@ -3,20 +3,27 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/satonic/satonic-api/internal/models"
+	"github.com/satonic/satonic-api/internal/ord"
 	"github.com/satonic/satonic-api/internal/store"
 )
 
 // NFTService handles NFT-related operations
 type NFTService struct {
-	nftRepo *store.NFTRepository
+	nftRepo  *store.NFTRepository
+	userRepo *store.UserRepository
+	indexer  ord.Indexer
 }
 
 // NewNFTService creates a new NFTService
-func NewNFTService(nftRepo *store.NFTRepository) *NFTService {
+func NewNFTService(nftRepo *store.NFTRepository, userRepo *store.UserRepository, indexer ord.Indexer) *NFTService {
 	return &NFTService{
-		nftRepo: nftRepo,
+		nftRepo:  nftRepo,
+		userRepo: userRepo,
+		indexer:  indexer,
 	}
 }
 
@@ -25,6 +32,12 @@ func (s *NFTService) GetByID(id string) (*models.NFT, error) {
 	return s.nftRepo.GetByID(id)
 }
 
+// GetCollectionStats retrieves the market summary of a collection, including
+// its floor price and best collection offer
+func (s *NFTService) GetCollectionStats(collection string) (*models.CollectionStats, error) {
+	return s.nftRepo.GetCollectionStats(collection, time.Now())
+}
+
 // GetByWalletID retrieves NFTs owned by a wallet
 func (s *NFTService) GetByWalletID(walletID string, params models.NFTParams) (*models.NFTListResponse, error) {
 	nfts, total, err := s.nftRepo.GetByWalletID(walletID, params)
@@ -65,39 +78,101 @@ func (s *NFTService) Update(nft *models.NFT) error {
 	return s.nftRepo.Update(nft)
 }
 
-// ValidateOrdinal validates an ordinal inscription
+// ValidateOrdinal checks that an inscription exists and is held by a wallet address
 func (s *NFTService) ValidateOrdinal(inscriptionID, walletAddress string) (bool, error) {
-	// In a real implementation, you would:
-	// 1. Query a Bitcoin node or API to check the inscription
-	// 2. Verify the inscription belongs to the wallet address
-	// 3. Parse the metadata to ensure it's a valid NFT
+	inscription, err := s.indexer.GetInscription(inscriptionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch inscription: %w", err)
+	}
 
-	// This is a placeholder for demo purposes
-	return true, nil
+	if inscription == nil {
+		return false, fmt.Errorf("inscription not found")
+	}
+
+	return inscription.Address == walletAddress, nil
 }
 
-// ImportOrdinal imports an ordinal as an NFT
+// ImportOrdinal imports an ordinal held by one of the user's wallets as an NFT
 func (s *NFTService) ImportOrdinal(walletID, inscriptionID string) (*models.NFT, error) {
-	// In a real implementation, you would:
-	// 1. Fetch the inscription details from a Bitcoin node or API
-	// 2. Parse the metadata to extract NFT information
-	// 3. Create a new NFT record
+	// Get the wallet that should hold the inscription
+	wallet, err := s.userRepo.GetWalletByID(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		return nil, fmt.Errorf("wallet not found")
+	}
+
+	// Fetch the inscription details from the indexer
+	inscription, err := s.indexer.GetInscription(inscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch inscription: %w", err)
+	}
+
+	if inscription == nil {
+		return nil, fmt.Errorf("inscription not found")
+	}
+
+	// Verify ownership before creating the NFT
+	if inscription.Address != wallet.Address {
+		return nil, fmt.Errorf("inscription is not owned by the wallet")
+	}
 
-	// This is a placeholder for demo purposes
+	parents, err := s.indexer.GetParents(inscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch inscription parents: %w", err)
+	}
+
+	children, err := s.indexer.GetChildren(inscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch inscription children: %w", err)
+	}
+
+	metadata, err := json.Marshal(map[string]interface{}{
+		"type":           "ordinal",
+		"number":         inscription.Number,
+		"content_length": inscription.ContentLength,
+		"timestamp":      inscription.Timestamp,
+		"parents":        parents,
+		"children":       children,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Children of a parent inscription belong to the parent's collection
+	collection := "Ordinals"
+	if len(parents) > 0 {
+		collection = parents[0]
+	}
+
+	contentURL := s.indexer.ContentURL(inscriptionID)
 	nft := &models.NFT{
 		WalletID:      walletID,
 		InscriptionID: inscriptionID,
 		TokenID:       inscriptionID, // Using inscription ID as token ID
-		Collection:    "Ordinals",
-		Title:         "Ordinal #" + inscriptionID[:8],
+		Collection:    collection,
+		Title:         fmt.Sprintf("Inscription #%d", inscription.Number),
 		Description:   "An Ordinal inscription",
-		ImageURL:      "https://example.com/ordinals/" + inscriptionID + ".png",
-		ContentURL:    "https://example.com/ordinals/" + inscriptionID + ".json",
-		Metadata:      json.RawMessage(`{"type":"ordinal","rarity":"common"}`),
+		ContentURL:    contentURL,
+		Metadata:      json.RawMessage(metadata),
+		Location:      inscription.Satpoint,
+		ContentType:   inscription.ContentType,
+		GenesisHeight: inscription.Height,
+	}
+
+	if strings.HasPrefix(inscription.ContentType, "image/") {
+		nft.ImageURL = contentURL
+	}
+
+	if inscription.Sat != nil {
+		satNumber := int64(*inscription.Sat)
+		nft.SatNumber = &satNumber
 	}
 
 	// Save the NFT
-	err := s.Create(nft)
+	err = s.Create(nft)
 	if err != nil {
 		return nil, fmt.Errorf("failed to import ordinal: %w", err)
 	}
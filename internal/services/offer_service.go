@@ -0,0 +1,520 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/satonic/satonic-api/internal/config"
+	"github.com/satonic/satonic-api/internal/models"
+	"github.com/satonic/satonic-api/internal/store"
+)
+
+// OfferService handles offers on NFTs that are not on auction
+type OfferService struct {
+	offerRepo     *store.OfferRepository
+	nftRepo       *store.NFTRepository
+	userRepo      *store.UserRepository
+	walletService *WalletService
+	fees          feeSchedule
+}
+
+// NewOfferService creates a new OfferService
+func NewOfferService(offerRepo *store.OfferRepository, nftRepo *store.NFTRepository, userRepo *store.UserRepository, walletService *WalletService, fees config.FeeConfig) *OfferService {
+	return &OfferService{
+		offerRepo:     offerRepo,
+		nftRepo:       nftRepo,
+		userRepo:      userRepo,
+		walletService: walletService,
+		fees:          feeSchedule{cfg: fees},
+	}
+}
+
+// GetByID retrieves an offer by ID
+func (s *OfferService) GetByID(id string) (*models.Offer, error) {
+	return s.offerRepo.GetByID(id)
+}
+
+// List retrieves offers with filtering and pagination
+func (s *OfferService) List(params models.OfferParams) (*models.OfferListResponse, error) {
+	offers, total, err := s.offerRepo.List(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OfferListResponse{
+		Offers:     offers,
+		TotalCount: total,
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+	}, nil
+}
+
+// CreateOffer makes an offer on an NFT and builds the offer PSBT for the
+// offerer to sign. The offer opens once the signed PSBT is submitted.
+func (s *OfferService) CreateOffer(req models.CreateOfferRequest, userID string) (*models.CreateOfferResponse, error) {
+	if req.Amount < dustLimit {
+		return nil, fmt.Errorf("amount must be at least %d sats", dustLimit)
+	}
+
+	if !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expiry must be in the future")
+	}
+
+	// Verify wallet belongs to user
+	wallet, err := s.userWallet(userID, req.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the NFT
+	nft, err := s.nftRepo.GetByID(req.NFTID)
+	if err != nil {
+		return nil, err
+	}
+
+	if nft == nil {
+		return nil, fmt.Errorf("NFT not found")
+	}
+
+	if nft.Location == "" {
+		return nil, fmt.Errorf("NFT location is unknown")
+	}
+
+	ownerWallet, err := s.userRepo.GetWalletByID(nft.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownerWallet == nil {
+		return nil, fmt.Errorf("owner wallet not found")
+	}
+
+	if ownerWallet.UserID == userID {
+		return nil, fmt.Errorf("you cannot make an offer on your own NFT")
+	}
+
+	// The inscription must go to one of the offerer's wallets so it can be tracked
+	receiveWallet := wallet
+	if req.ReceiveAddress != "" && req.ReceiveAddress != wallet.Address {
+		receiveWallet, err = s.userWalletByAddress(userID, req.ReceiveAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Build the offer PSBT, paying the fees out of the amount
+	fees, err := s.fees.settle(req.Amount, 0, []models.NFT{*nft})
+	if err != nil {
+		return nil, err
+	}
+
+	offerPSBT, err := s.walletService.BuildOfferPSBT(OfferPSBTParams{
+		Location:       nft.Location,
+		OwnerAddress:   ownerWallet.Address,
+		BuyerAddress:   wallet.Address,
+		ReceiveAddress: receiveWallet.Address,
+		Amount:         req.Amount,
+		Payouts:        s.fees.payouts(fees),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	offer := &models.Offer{
+		NFTID:           nft.ID,
+		OffererID:       userID,
+		WalletID:        wallet.ID,
+		ReceiveWalletID: receiveWallet.ID,
+		OwnerWalletID:   ownerWallet.ID,
+		Amount:          req.Amount,
+		Location:        nft.Location,
+		PSBT:            offerPSBT.PSBT,
+		Status:          models.OfferStatusPending,
+		ExpiresAt:       req.ExpiresAt,
+	}
+
+	if err := s.offerRepo.Create(offer); err != nil {
+		return nil, err
+	}
+
+	return &models.CreateOfferResponse{
+		Offer: offer,
+		PSBT:  offerPSBT,
+	}, nil
+}
+
+// SignOffer funds a pending offer with the offer PSBT signed by the offerer
+func (s *OfferService) SignOffer(req models.SignOfferRequest, userID string) (*models.Offer, error) {
+	offer, err := s.offerRepo.GetByID(req.OfferID)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer == nil || offer.OffererID != userID {
+		return nil, fmt.Errorf("offer not found")
+	}
+
+	if offer.Status != models.OfferStatusPending {
+		return nil, fmt.Errorf("offer is not waiting for signatures")
+	}
+
+	merged, err := s.walletService.MergeOfferSignatures(offer.PSBT, req.PSBT, offer.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	opened, err := s.offerRepo.Open(offer.ID, merged)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opened {
+		return nil, fmt.Errorf("offer is not waiting for signatures")
+	}
+
+	return s.offerRepo.GetByID(offer.ID)
+}
+
+// AcceptOffer accepts an open offer and returns the offer PSBT for the owner
+// to sign. The NFT must not have moved, must not be on auction and the
+// offerer's funding must still be unspent.
+func (s *OfferService) AcceptOffer(offerID, userID string) (*models.SettlementPSBT, error) {
+	offer, err := s.getOwnerOffer(offerID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accepting again returns the same PSBT
+	if offer.Status != models.OfferStatusAccepted {
+		if offer.Status != models.OfferStatusOpen {
+			return nil, fmt.Errorf("offer is not open")
+		}
+
+		nft, err := s.nftRepo.GetByID(offer.NFTID)
+		if err != nil {
+			return nil, err
+		}
+
+		if nft == nil {
+			return nil, fmt.Errorf("NFT not found")
+		}
+
+		if nft.AuctionID != nil {
+			return nil, fmt.Errorf("NFT is on auction")
+		}
+
+		reason, err := s.offerInvalidity(offer, nft)
+		if err != nil {
+			return nil, err
+		}
+
+		if reason != "" {
+			s.invalidate(offer, reason)
+			return nil, fmt.Errorf("offer is no longer valid: %s", reason)
+		}
+
+		accepted, err := s.offerRepo.Accept(offer.ID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		if !accepted {
+			return nil, fmt.Errorf("offer is no longer open")
+		}
+	}
+
+	parsed, err := s.walletService.ParsePSBT(offer.PSBT)
+	if err != nil {
+		return nil, err
+	}
+
+	settlement := &models.SettlementPSBT{
+		PSBT: offer.PSBT,
+		TxID: parsed.TxID,
+	}
+	if parsed.Fee != nil {
+		settlement.Fee = *parsed.Fee
+	}
+
+	for i, input := range parsed.Inputs {
+		if spendsLocation(input, offer.Location) {
+			settlement.InputsToSign = append(settlement.InputsToSign, i)
+		}
+	}
+
+	return settlement, nil
+}
+
+// FinalizeOffer merges the owner's signature into an accepted offer and
+// broadcasts the settlement transaction
+func (s *OfferService) FinalizeOffer(req models.FinalizeOfferRequest, userID string) (*models.Offer, error) {
+	offer, err := s.getOwnerOffer(req.OfferID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer.Status != models.OfferStatusAccepted {
+		return nil, fmt.Errorf("offer has not been accepted")
+	}
+
+	if !time.Now().Before(offer.ExpiresAt) {
+		return nil, fmt.Errorf("offer has expired")
+	}
+
+	// Merge the owner's signature and extract the final transaction
+	rawTx, txid, err := s.walletService.FinalizePurchasePSBT(offer.PSBT, req.PSBT)
+	if err != nil {
+		return nil, err
+	}
+
+	// Broadcast the settlement transaction
+	broadcastTxID, err := s.walletService.BroadcastTransaction(rawTx)
+	if err != nil {
+		return nil, err
+	}
+
+	if broadcastTxID != "" {
+		txid = broadcastTxID
+	}
+
+	// Wait for the settlement transaction to confirm
+	if err := s.offerRepo.StartSettlement(offer.ID, txid); err != nil {
+		return nil, err
+	}
+
+	offer.Status = models.OfferStatusSettling
+	offer.SettlementTxID = &txid
+
+	return offer, nil
+}
+
+// RejectOffer rejects an offer on one of the user's NFTs
+func (s *OfferService) RejectOffer(offerID, userID string) error {
+	if _, err := s.getOwnerOffer(offerID, userID); err != nil {
+		return err
+	}
+
+	rejected, err := s.offerRepo.TransitionStatus(offerID, models.OfferStatusRejected, "",
+		models.OfferStatusPending, models.OfferStatusOpen, models.OfferStatusAccepted)
+	if err != nil {
+		return err
+	}
+
+	if !rejected {
+		return fmt.Errorf("offer can no longer be rejected")
+	}
+
+	return nil
+}
+
+// CancelOffer withdraws one of the user's offers
+func (s *OfferService) CancelOffer(offerID, userID string) error {
+	offer, err := s.offerRepo.GetByID(offerID)
+	if err != nil {
+		return err
+	}
+
+	if offer == nil || offer.OffererID != userID {
+		return fmt.Errorf("offer not found")
+	}
+
+	cancelled, err := s.offerRepo.TransitionStatus(offerID, models.OfferStatusCancelled, "",
+		models.OfferStatusPending, models.OfferStatusOpen, models.OfferStatusAccepted)
+	if err != nil {
+		return err
+	}
+
+	if !cancelled {
+		return fmt.Errorf("offer can no longer be cancelled")
+	}
+
+	return nil
+}
+
+// CheckOffers expires offers and collection offers whose expiry has passed
+// and invalidates funded offers whose NFT moved or whose funding UTXOs were
+// spent
+func (s *OfferService) CheckOffers(now time.Time) error {
+	if err := s.offerRepo.ExpireOffers(now); err != nil {
+		return err
+	}
+
+	if err := s.offerRepo.ExpireCollectionOffers(now); err != nil {
+		return err
+	}
+
+	offers, err := s.offerRepo.GetFundedOffers()
+	if err != nil {
+		return err
+	}
+
+	for i := range offers {
+		offer := &offers[i]
+
+		nft, err := s.nftRepo.GetByID(offer.NFTID)
+		if err != nil {
+			log.Printf("error checking offer %s: %v", offer.ID, err)
+			continue
+		}
+
+		reason, err := s.offerInvalidity(offer, nft)
+		if err != nil {
+			log.Printf("error checking offer %s: %v", offer.ID, err)
+			continue
+		}
+
+		if reason != "" {
+			s.invalidate(offer, reason)
+		}
+	}
+
+	return nil
+}
+
+// CheckSettlements moves settling offers to settled once their settlement
+// transaction has enough confirmations, or invalidates them when it was
+// replaced or dropped
+func (s *OfferService) CheckSettlements(requiredConfirmations int) error {
+	offers, err := s.offerRepo.GetSettlingOffers()
+	if err != nil {
+		return err
+	}
+
+	for _, offer := range offers {
+		if err := s.checkSettlement(offer, requiredConfirmations); err != nil {
+			log.Printf("error checking settlement of offer %s: %v", offer.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// checkSettlement checks the settlement transaction of a single offer
+func (s *OfferService) checkSettlement(offer models.Offer, requiredConfirmations int) error {
+	if offer.SettlementTxID == nil {
+		return fmt.Errorf("offer has no settlement transaction")
+	}
+
+	status, err := s.walletService.GetSettlementStatus(*offer.SettlementTxID, offer.Location)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status.Dropped:
+		_, err = s.offerRepo.TransitionStatus(offer.ID, models.OfferStatusInvalidated,
+			"settlement transaction was dropped from the mempool", models.OfferStatusSettling)
+		return err
+
+	case status.ConflictTxID != "":
+		_, err = s.offerRepo.TransitionStatus(offer.ID, models.OfferStatusInvalidated,
+			"settlement transaction was replaced by "+status.ConflictTxID, models.OfferStatusSettling)
+		return err
+
+	case status.Confirmations >= int64(requiredConfirmations):
+		location, err := SettlementLocation(offer.PSBT, offer.Location, *offer.SettlementTxID)
+		if err != nil {
+			return err
+		}
+
+		// Transfer the NFT to the offerer's wallet
+		return s.offerRepo.CompleteSettlement(offer.ID, offer.NFTID, offer.ReceiveWalletID, location)
+	}
+
+	return nil
+}
+
+// offerInvalidity returns why a funded offer can no longer settle, or an empty
+// string if it still can
+func (s *OfferService) offerInvalidity(offer *models.Offer, nft *models.NFT) (string, error) {
+	if nft == nil || nft.WalletID != offer.OwnerWalletID || nft.Location != offer.Location {
+		return "NFT has moved", nil
+	}
+
+	spent, err := s.walletService.SpentInput(offer.PSBT)
+	if err != nil {
+		return "", err
+	}
+
+	if spent < 0 {
+		return "", nil
+	}
+
+	parsed, err := s.walletService.ParsePSBT(offer.PSBT)
+	if err != nil {
+		return "", err
+	}
+
+	if spendsLocation(parsed.Inputs[spent], offer.Location) {
+		return "NFT has moved", nil
+	}
+
+	return "funding UTXOs were spent", nil
+}
+
+// invalidate marks a live offer as invalid
+func (s *OfferService) invalidate(offer *models.Offer, reason string) {
+	_, err := s.offerRepo.TransitionStatus(offer.ID, models.OfferStatusInvalidated, reason,
+		models.OfferStatusPending, models.OfferStatusOpen, models.OfferStatusAccepted)
+	if err != nil {
+		log.Printf("error invalidating offer %s: %v", offer.ID, err)
+	}
+}
+
+// getOwnerOffer retrieves an offer on an NFT held by one of the user's wallets
+func (s *OfferService) getOwnerOffer(offerID, userID string) (*models.Offer, error) {
+	offer, err := s.offerRepo.GetByID(offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer == nil {
+		return nil, fmt.Errorf("offer not found")
+	}
+
+	if _, err := s.userWallet(userID, offer.OwnerWalletID); err != nil {
+		return nil, fmt.Errorf("only the owner of the NFT can respond to the offer")
+	}
+
+	return offer, nil
+}
+
+// userWallet returns the user's wallet with the given ID
+func (s *OfferService) userWallet(userID, walletID string) (*models.Wallet, error) {
+	wallets, err := s.userRepo.GetWalletsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range wallets {
+		if wallets[i].ID == walletID {
+			return &wallets[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("wallet not found or not owned by user")
+}
+
+// userWalletByAddress returns the user's wallet with the given address
+func (s *OfferService) userWalletByAddress(userID, address string) (*models.Wallet, error) {
+	wallets, err := s.userRepo.GetWalletsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range wallets {
+		if wallets[i].Address == address {
+			return &wallets[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("receive address must belong to one of your wallets")
+}
+
+// spendsLocation reports whether a PSBT input spends the output holding a
+// satpoint
+func spendsLocation(input models.PSBTInput, location string) bool {
+	return strings.HasPrefix(location, fmt.Sprintf("%s:%d:", input.TxID, input.Vout))
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/satonic/satonic-api/internal/config"
+)
+
+// OfferWatcher expires and invalidates offers and polls the chain backend for
+// the confirmations of offer settlement transactions
+type OfferWatcher struct {
+	offerService  *OfferService
+	confirmations int
+	interval      time.Duration
+}
+
+// NewOfferWatcher creates a new OfferWatcher
+func NewOfferWatcher(offerService *OfferService, cfg config.BitcoinConfig) *OfferWatcher {
+	confirmations := cfg.SettlementConfirmations
+	if confirmations <= 0 {
+		confirmations = 1
+	}
+
+	interval := time.Duration(cfg.SettlementPollInterval) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	return &OfferWatcher{
+		offerService:  offerService,
+		confirmations: confirmations,
+		interval:      interval,
+	}
+}
+
+// Run checks offers on every tick until the context is cancelled
+func (w *OfferWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.offerService.CheckOffers(time.Now()); err != nil {
+				log.Printf("error checking offers: %v", err)
+			}
+
+			if err := w.offerService.CheckSettlements(w.confirmations); err != nil {
+				log.Printf("error checking offer settlements: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/satonic/satonic-api/internal/chain"
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// psbtHexMagic is the hex encoding of the PSBT magic bytes
+const psbtHexMagic = "70736274ff"
+
+// PSBT validation error codes
+const (
+	PSBTErrInvalidEncoding   = "invalid_encoding"
+	PSBTErrInvalidLocation   = "invalid_location"
+	PSBTErrInscriptionInput  = "inscription_input_missing"
+	PSBTErrMissingUTXO       = "missing_utxo"
+	PSBTErrInputNotOwned     = "input_not_owned"
+	PSBTErrInvalidOffset     = "invalid_offset"
+	PSBTErrNotSigned         = "input_not_signed"
+	PSBTErrInvalidSighash    = "invalid_sighash"
+	PSBTErrInvalidSignature  = "invalid_signature"
+	PSBTErrPaymentMissing    = "payment_output_missing"
+	PSBTErrInvalidPayment    = "invalid_payment"
+	PSBTErrInvalidSellerAddr = "invalid_seller_address"
+	PSBTErrTxMismatch        = "transaction_mismatch"
+)
+
+const (
+	// dustLimit is the smallest output value created by the server
+	dustLimit = 546
+
+	// settlementConfTarget is the confirmation target used to pick a fee rate
+	settlementConfTarget = 3
+
+	// txOverheadVSize is the virtual size of a transaction without inputs or outputs
+	txOverheadVSize = 11
+)
+
+// listingSighash is the sighash type a seller must use to sign a listing so
+// that buyers can add their own inputs and outputs
+const listingSighash = txscript.SigHashSingle | txscript.SigHashAnyOneCanPay
+
+// PSBTValidationError describes why a PSBT was rejected
+type PSBTValidationError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Input   *int   `json:"input,omitempty"`
+}
+
+// Error implements the error interface
+func (e *PSBTValidationError) Error() string {
+	if e.Input != nil {
+		return fmt.Sprintf("invalid PSBT: input %d: %s", *e.Input, e.Message)
+	}
+	return "invalid PSBT: " + e.Message
+}
+
+// newPSBTError creates a PSBTValidationError for a specific input
+func newPSBTError(code string, input int, format string, args ...interface{}) *PSBTValidationError {
+	return &PSBTValidationError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Input:   &input,
+	}
+}
+
+// ListingPSBTParams describes what a seller's listing PSBT must commit to
+type ListingPSBTParams struct {
+	InscriptionID string
+	Location      string // satpoint of the inscription, txid:vout:offset
+	SellerAddress string
+	Price         int64 // in satoshis
+	// Further inscriptions sold with the first one as a lot. Each is spent by
+	// its own input and the paired outputs pay the price between them.
+	Lot []ListedInscription
+}
+
+// ListedInscription is an inscription sold by a listing PSBT
+type ListedInscription struct {
+	InscriptionID string
+	Location      string // satpoint of the inscription, txid:vout:offset
+}
+
+// decodePSBT decodes a base64 or hex encoded PSBT
+func decodePSBT(encoded string) (*psbt.Packet, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	if strings.HasPrefix(strings.ToLower(encoded), psbtHexMagic) {
+		raw, err := hex.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		return psbt.NewFromRawBytes(bytes.NewReader(raw), false)
+	}
+
+	return psbt.NewFromRawBytes(strings.NewReader(encoded), true)
+}
+
+// parseSatpoint parses an inscription satpoint of the form txid:vout:offset
+func parseSatpoint(satpoint string) (wire.OutPoint, int64, error) {
+	parts := strings.Split(satpoint, ":")
+	if len(parts) != 3 {
+		return wire.OutPoint{}, 0, fmt.Errorf("invalid satpoint %q", satpoint)
+	}
+
+	hash, err := chainhash.NewHashFromStr(parts[0])
+	if err != nil {
+		return wire.OutPoint{}, 0, fmt.Errorf("invalid satpoint txid: %w", err)
+	}
+
+	vout, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return wire.OutPoint{}, 0, fmt.Errorf("invalid satpoint vout: %w", err)
+	}
+
+	offset, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || offset < 0 {
+		return wire.OutPoint{}, 0, fmt.Errorf("invalid satpoint offset %q", parts[2])
+	}
+
+	return *wire.NewOutPoint(hash, uint32(vout)), offset, nil
+}
+
+// findInput returns the index of the PSBT input spending an outpoint, or -1
+func findInput(packet *psbt.Packet, outPoint wire.OutPoint) int {
+	for i, txIn := range packet.UnsignedTx.TxIn {
+		if txIn.PreviousOutPoint == outPoint {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// inputUTXO returns the output spent by a PSBT input, if the PSBT includes it
+func inputUTXO(packet *psbt.Packet, index int) *wire.TxOut {
+	in := packet.Inputs[index]
+	if in.WitnessUtxo != nil {
+		return in.WitnessUtxo
+	}
+
+	if in.NonWitnessUtxo != nil {
+		prevOut := packet.UnsignedTx.TxIn[index].PreviousOutPoint
+		if in.NonWitnessUtxo.TxHash() == prevOut.Hash &&
+			int(prevOut.Index) < len(in.NonWitnessUtxo.TxOut) {
+			return in.NonWitnessUtxo.TxOut[prevOut.Index]
+		}
+	}
+
+	return nil
+}
+
+// isInputSigned reports whether a PSBT input carries a signature
+func isInputSigned(in *psbt.PInput) bool {
+	return len(in.FinalScriptWitness) > 0 || len(in.FinalScriptSig) > 0 ||
+		len(in.TaprootKeySpendSig) > 0 || len(in.PartialSigs) > 0 ||
+		len(in.TaprootScriptSpendSig) > 0
+}
+
+// toPSBTOutput converts a transaction output to its model representation
+func (s *WalletService) toPSBTOutput(txOut *wire.TxOut) *models.PSBTOutput {
+	output := &models.PSBTOutput{
+		Value:  txOut.Value,
+		Script: hex.EncodeToString(txOut.PkScript),
+	}
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(txOut.PkScript, s.params)
+	if err == nil && len(addrs) == 1 {
+		output.Address = addrs[0].EncodeAddress()
+	}
+
+	return output
+}
+
+// verifyInputSignature finalizes a copy of a PSBT input and runs it through
+// the script engine. It returns the sighash type the input was signed with.
+func (s *WalletService) verifyInputSignature(packet *psbt.Packet, index int) (txscript.SigHashType, error) {
+	// Work on a copy so the caller's packet is left untouched
+	var buf bytes.Buffer
+	if err := packet.Serialize(&buf); err != nil {
+		return 0, err
+	}
+	packetCopy, err := psbt.NewFromRawBytes(&buf, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := psbt.MaybeFinalize(packetCopy, index); err != nil {
+		return 0, err
+	}
+
+	in := packetCopy.Inputs[index]
+	tx := packetCopy.UnsignedTx.Copy()
+	tx.TxIn[index].SignatureScript = in.FinalScriptSig
+
+	// The signature is the first witness item, or the first push of the
+	// script sig for legacy inputs
+	var sig []byte
+	if len(in.FinalScriptWitness) > 0 {
+		witness, err := parseWitness(in.FinalScriptWitness)
+		if err != nil {
+			return 0, err
+		}
+		tx.TxIn[index].Witness = witness
+		sig = witness[0]
+	} else if len(in.FinalScriptSig) > 0 {
+		pushes, err := txscript.PushedData(in.FinalScriptSig)
+		if err != nil || len(pushes) == 0 {
+			return 0, fmt.Errorf("invalid script sig")
+		}
+		sig = pushes[0]
+	}
+
+	// 64 byte Schnorr signatures use the default sighash
+	sigHashType := txscript.SigHashDefault
+	if len(sig) != 64 && len(sig) > 0 {
+		sigHashType = txscript.SigHashType(sig[len(sig)-1])
+	}
+
+	// Inputs without a known UTXO are given a placeholder; they are not
+	// committed to by ANYONECANPAY signatures
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range tx.TxIn {
+		utxo := inputUTXO(packetCopy, i)
+		if utxo == nil {
+			utxo = wire.NewTxOut(0, nil)
+		}
+		prevOuts.AddPrevOut(txIn.PreviousOutPoint, utxo)
+	}
+
+	utxo := inputUTXO(packetCopy, index)
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+	engine, err := txscript.NewEngine(
+		utxo.PkScript, tx, index, txscript.StandardVerifyFlags, nil, sigHashes, utxo.Value, prevOuts,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := engine.Execute(); err != nil {
+		return 0, err
+	}
+
+	return sigHashType, nil
+}
+
+// inputVSize estimates the virtual size of an input spending a script
+func inputVSize(pkScript []byte) int64 {
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.WitnessV1TaprootTy:
+		return 58
+	case txscript.WitnessV0PubKeyHashTy:
+		return 68
+	case txscript.ScriptHashTy:
+		// Assume a nested P2WPKH
+		return 91
+	default:
+		return 148
+	}
+}
+
+// outputVSize estimates the virtual size of an output
+func outputVSize(pkScript []byte) int64 {
+	return int64(8 + 1 + len(pkScript))
+}
+
+// utxoTxIn creates a transaction input spending a UTXO
+func utxoTxIn(utxo chain.UTXO, sequence uint32) (*wire.TxIn, error) {
+	hash, err := chainhash.NewHashFromStr(utxo.TxID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UTXO txid: %w", err)
+	}
+
+	txIn := wire.NewTxIn(wire.NewOutPoint(hash, utxo.Vout), nil, nil)
+	txIn.Sequence = sequence
+	return txIn, nil
+}
+
+// copyInputSignatures copies the signature fields of a PSBT input
+func copyInputSignatures(dst, src *psbt.PInput) {
+	dst.PartialSigs = src.PartialSigs
+	dst.TaprootKeySpendSig = src.TaprootKeySpendSig
+	dst.TaprootScriptSpendSig = src.TaprootScriptSpendSig
+	dst.FinalScriptSig = src.FinalScriptSig
+	dst.FinalScriptWitness = src.FinalScriptWitness
+}
+
+// SettlementLocation returns the satpoint of an inscription once a settlement
+// transaction built by BuildPurchasePSBT confirms. The leading buyer input and
+// the seller's inputs all flow into the first output, so the inscription lands
+// there after the value of the inputs spent before its own.
+func SettlementLocation(settlementPSBT, location, txid string) (string, error) {
+	packet, err := decodePSBT(settlementPSBT)
+	if err != nil {
+		return "", err
+	}
+
+	outPoint, offset, err := parseSatpoint(location)
+	if err != nil {
+		return "", err
+	}
+
+	index := findInput(packet, outPoint)
+	if index < 1 {
+		return "", fmt.Errorf("settlement PSBT does not spend the inscription")
+	}
+
+	for i := 0; i < index; i++ {
+		utxo := inputUTXO(packet, i)
+		if utxo == nil {
+			return "", fmt.Errorf("settlement PSBT is missing the UTXO of input %d", i)
+		}
+		offset += utxo.Value
+	}
+
+	return fmt.Sprintf("%s:0:%d", txid, offset), nil
+}
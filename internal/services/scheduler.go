@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/satonic/satonic-api/internal/config"
+)
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is a Clock backed by the system time
+type SystemClock struct{}
+
+// Now returns the current system time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// LeaderLock elects a single leader among API replicas
+type LeaderLock interface {
+	// TryAcquire takes the lock if it is free and reports whether this process holds it
+	TryAcquire(ctx context.Context) (bool, error)
+
+	// Release releases the lock if this process holds it
+	Release(ctx context.Context) error
+}
+
+// AuctionScheduler activates draft auctions, closes ended auctions and reveal
+// phases, cancels sales left unsettled and publishes Dutch auction prices on
+// the replica holding the leader lock
+type AuctionScheduler struct {
+	auctionService *AuctionService
+	lock           LeaderLock
+	clock          Clock
+	interval       time.Duration
+	lastTick       time.Time
+}
+
+// NewAuctionScheduler creates a new AuctionScheduler
+func NewAuctionScheduler(auctionService *AuctionService, lock LeaderLock, clock Clock, cfg config.SchedulerConfig) *AuctionScheduler {
+	interval := time.Duration(cfg.TickInterval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	return &AuctionScheduler{
+		auctionService: auctionService,
+		lock:           lock,
+		clock:          clock,
+		interval:       interval,
+	}
+}
+
+// Run ticks until the context is cancelled, then gives up leadership
+func (s *AuctionScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	defer func() {
+		if err := s.lock.Release(context.Background()); err != nil {
+			log.Printf("error releasing scheduler lock: %v", err)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Tick(ctx); err != nil {
+				log.Printf("error running auction scheduler: %v", err)
+			}
+		}
+	}
+}
+
+// Tick runs a single scheduling pass if this replica is the leader
+func (s *AuctionScheduler) Tick(ctx context.Context) error {
+	leader, err := s.lock.TryAcquire(ctx)
+	if err != nil || !leader {
+		return err
+	}
+
+	now := s.clock.Now()
+
+	if err := s.auctionService.ActivateDueAuctions(now); err != nil {
+		return err
+	}
+
+	if err := s.auctionService.ProcessEndedAuctions(now); err != nil {
+		return err
+	}
+
+	if err := s.auctionService.ProcessRevealedAuctions(now); err != nil {
+		return err
+	}
+
+	if err := s.auctionService.ExpireSettlements(now); err != nil {
+		return err
+	}
+
+	// Push the Dutch auction prices that stepped since the last pass
+	last := s.lastTick
+	s.lastTick = now
+
+	return s.auctionService.PublishDutchPrices(now, last)
+}
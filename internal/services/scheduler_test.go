@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/satonic/satonic-api/internal/chain"
+	"github.com/satonic/satonic-api/internal/config"
+	"github.com/satonic/satonic-api/internal/models"
+	"github.com/satonic/satonic-api/internal/store"
+)
+
+// fakeClock is a Clock the test moves by hand
+type fakeClock struct {
+	now time.Time
+}
+
+// Now returns the time the test set
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// fakeLock is a LeaderLock held whenever the test says so
+type fakeLock struct {
+	held     bool
+	attempts int
+}
+
+// TryAcquire reports whether the lock is held
+func (l *fakeLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.attempts++
+	return l.held, nil
+}
+
+// Release gives up the lock
+func (l *fakeLock) Release(ctx context.Context) error {
+	l.held = false
+	return nil
+}
+
+// recordingNotifier records the status and price notifications of each auction
+type recordingNotifier struct {
+	notifications map[string][]string
+}
+
+// NotifyAuction records a notification
+func (n *recordingNotifier) NotifyAuction(auctionID, messageType string, payload interface{}) {
+	switch update := payload.(type) {
+	case models.AuctionStatusUpdate:
+		n.notifications[auctionID] = append(n.notifications[auctionID], fmt.Sprintf("%s %s", messageType, update.Status))
+	case models.AuctionPriceUpdate:
+		n.notifications[auctionID] = append(n.notifications[auctionID], fmt.Sprintf("%s %d", messageType, update.CurrentPrice))
+	}
+}
+
+// take returns the notifications of an auction since the last call
+func (n *recordingNotifier) take(auctionID string) []string {
+	notifications := n.notifications[auctionID]
+	delete(n.notifications, auctionID)
+	return notifications
+}
+
+func TestAuctionSchedulerFollower(t *testing.T) {
+	// A follower never reaches the service
+	lock := &fakeLock{}
+	scheduler := NewAuctionScheduler(&AuctionService{}, lock, &fakeClock{now: time.Now()}, config.SchedulerConfig{})
+
+	for i := 0; i < 3; i++ {
+		if err := scheduler.Tick(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if lock.attempts != 3 {
+		t.Errorf("got %d lock attempts, want 3", lock.attempts)
+	}
+}
+
+func TestAuctionSchedulerTick(t *testing.T) {
+	db := testDatabase(t)
+
+	auctionRepo := store.NewAuctionRepository(db)
+	nftRepo := store.NewNFTRepository(db)
+	userRepo := store.NewUserRepository(db)
+
+	fake := chain.NewFakeBackend(&chaincfg.MainNetParams)
+	walletService := NewWalletService(config.BitcoinConfig{}, fake)
+	auctionService := NewAuctionService(auctionRepo, nftRepo, userRepo, walletService,
+		config.AuctionConfig{}, config.FeeConfig{})
+
+	notifier := &recordingNotifier{notifications: make(map[string][]string)}
+	auctionService.SetNotifier(notifier)
+
+	seller := newTestBidder(t, userRepo, fake)
+
+	// Both auctions end within the hour so the active ones stay visible
+	base := time.Now().Truncate(time.Second)
+
+	english := &models.Auction{
+		Type:           models.AuctionTypeEnglish,
+		NFTID:          newTestNFT(t, nftRepo, seller.wallet, "Scheduled English").ID,
+		SellerWalletID: seller.wallet.ID,
+		StartPrice:     10_000,
+		StartTime:      base.Add(time.Minute),
+		EndTime:        base.Add(time.Hour),
+		Status:         models.AuctionStatusDraft,
+		PSBT:           "listing",
+	}
+	if err := auctionRepo.Create(english); err != nil {
+		t.Fatal(err)
+	}
+
+	// Six 10 minute steps from 60000 down to 10000 sats
+	floor := int64(10_000)
+	dutch := &models.Auction{
+		Type:              models.AuctionTypeDutch,
+		NFTID:             newTestNFT(t, nftRepo, seller.wallet, "Scheduled Dutch").ID,
+		SellerWalletID:    seller.wallet.ID,
+		StartPrice:        60_000,
+		FloorPrice:        &floor,
+		PriceSchedule:     models.PriceScheduleStepped,
+		PriceStepInterval: 600,
+		StartTime:         base,
+		EndTime:           base.Add(time.Hour),
+		Status:            models.AuctionStatusActive,
+		PSBT:              "listing",
+	}
+	if err := auctionRepo.Create(dutch); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := &fakeClock{}
+	scheduler := NewAuctionScheduler(auctionService, &fakeLock{held: true}, clock, config.SchedulerConfig{})
+
+	steps := []struct {
+		name          string
+		elapsed       time.Duration
+		englishStatus models.AuctionStatus
+		english       []string
+		dutch         []string
+	}{
+		{
+			name:          "before the start",
+			englishStatus: models.AuctionStatusDraft,
+			dutch:         []string{"price_update 60000"},
+		},
+		{
+			name:          "started",
+			elapsed:       5 * time.Minute,
+			englishStatus: models.AuctionStatusActive,
+			english:       []string{"auction_status active"},
+		},
+		{
+			name:          "first price step",
+			elapsed:       10 * time.Minute,
+			englishStatus: models.AuctionStatusActive,
+			dutch:         []string{"price_update 51667"},
+		},
+		{
+			name:          "same price step",
+			elapsed:       19 * time.Minute,
+			englishStatus: models.AuctionStatusActive,
+		},
+		{
+			name:          "ended without bids",
+			elapsed:       time.Hour + time.Second,
+			englishStatus: models.AuctionStatusCancelled,
+			english:       []string{"auction_status cancelled"},
+			dutch:         []string{"auction_status cancelled"},
+		},
+	}
+
+	for _, step := range steps {
+		clock.now = base.Add(step.elapsed)
+		if err := scheduler.Tick(context.Background()); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		if got := notifier.take(english.ID); !slices.Equal(got, step.english) {
+			t.Errorf("%s: got English notifications %v, want %v", step.name, got, step.english)
+		}
+		if got := notifier.take(dutch.ID); !slices.Equal(got, step.dutch) {
+			t.Errorf("%s: got Dutch notifications %v, want %v", step.name, got, step.dutch)
+		}
+
+		auction, err := auctionRepo.GetByID(english.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auction.Status != step.englishStatus {
+			t.Errorf("%s: got English status %s, want %s", step.name, auction.Status, step.englishStatus)
+		}
+	}
+}
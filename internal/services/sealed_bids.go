@@ -0,0 +1,202 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// minSaltLength keeps sealed bid amounts from being brute forced out of their
+// commitments
+const minSaltLength = 16
+
+// CommitmentHash returns the commitment of a sealed bid: the hex encoded
+// SHA-256 of "<amount>:<salt>"
+func CommitmentHash(amount int64, salt string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", amount, salt)))
+	return hex.EncodeToString(hash[:])
+}
+
+// CommitBid records a sealed bid commitment while a sealed-bid auction takes
+// bids. A bidder committing again replaces their earlier commitment.
+func (s *AuctionService) CommitBid(req models.CommitBidRequest, userID string) (*models.BidCommitment, error) {
+	// Verify wallet belongs to user
+	if _, err := s.bidderWallet(userID, req.WalletID); err != nil {
+		return nil, err
+	}
+
+	commitment := strings.ToLower(req.Commitment)
+	if decoded, err := hex.DecodeString(commitment); err != nil || len(decoded) != sha256.Size {
+		return nil, fmt.Errorf("commitment must be a hex encoded SHA-256 hash")
+	}
+
+	bidCommitment := &models.BidCommitment{
+		AuctionID:  req.AuctionID,
+		BidderID:   userID,
+		WalletID:   req.WalletID,
+		Commitment: commitment,
+	}
+
+	err := s.auctionRepo.CommitBid(bidCommitment, func(auction *models.Auction) error {
+		if auction.Type != models.AuctionTypeSealed {
+			return fmt.Errorf("auction does not take sealed bids")
+		}
+
+		now := time.Now()
+		if auction.Status != models.AuctionStatusActive || now.Before(auction.StartTime) {
+			return fmt.Errorf("auction is not active")
+		}
+
+		if now.After(auction.EndTime) {
+			return fmt.Errorf("auction has ended")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bidCommitment, nil
+}
+
+// RevealBid opens a sealed bid during the reveal phase of its auction. The
+// amount and salt must match the commitment and the committed wallet must
+// cover the amount.
+func (s *AuctionService) RevealBid(req models.RevealBidRequest, userID string) (*models.BidCommitment, error) {
+	if len(req.Salt) < minSaltLength {
+		return nil, fmt.Errorf("salt must be at least %d characters", minSaltLength)
+	}
+
+	// Get the commitment
+	existing, err := s.auctionRepo.GetBidCommitment(req.AuctionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		return nil, fmt.Errorf("no sealed bid to reveal")
+	}
+
+	// Check that the wallet can settle the bid
+	wallet, err := s.bidderWallet(userID, existing.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkBidFunding(userID, wallet, req.AuctionID, req.Amount); err != nil {
+		return nil, err
+	}
+
+	return s.auctionRepo.RevealBid(req.AuctionID, userID, func(auction *models.Auction, commitment *models.BidCommitment) error {
+		now := time.Now()
+		if auction.Status != models.AuctionStatusRevealing || auction.RevealEndTime == nil ||
+			now.After(*auction.RevealEndTime) {
+			return fmt.Errorf("auction is not in its reveal phase")
+		}
+
+		if commitment.Status != models.CommitmentStatusCommitted {
+			return fmt.Errorf("sealed bid has already been revealed")
+		}
+
+		if CommitmentHash(req.Amount, req.Salt) != commitment.Commitment {
+			return fmt.Errorf("amount and salt do not match the commitment")
+		}
+
+		if req.Amount < auction.StartPrice {
+			return fmt.Errorf("bid amount must be at least the start price")
+		}
+
+		amount := req.Amount
+		commitment.Amount = &amount
+		commitment.Status = models.CommitmentStatusRevealed
+		commitment.RevealedAt = &now
+
+		return nil
+	})
+}
+
+// ProcessRevealedAuctions closes sealed-bid auctions whose reveal phase has
+// passed. Unrevealed commitments are forfeited and the highest revealed bid
+// wins, or the auction is cancelled.
+func (s *AuctionService) ProcessRevealedAuctions(now time.Time) error {
+	auctions, err := s.auctionRepo.GetRevealClosedAuctions(now)
+	if err != nil {
+		return err
+	}
+
+	for _, auction := range auctions {
+		status, err := s.auctionRepo.CloseSealedAuction(auction.ID, now, sealedWinner)
+		if err != nil {
+			return err
+		}
+
+		if status != "" {
+			s.notifyStatus(models.AuctionStatusUpdate{
+				AuctionID: auction.ID,
+				Status:    status,
+			})
+		}
+	}
+
+	return nil
+}
+
+// sealedWinner picks the winning bid of a sealed-bid auction from its revealed
+// commitments, highest first. A first-price winner pays their own bid, a
+// second-price winner pays the runner-up's bid, or the start or reserve price
+// if higher. It returns nil if no bid meets the reserve price.
+func sealedWinner(auction *models.Auction, revealed []models.BidCommitment) (*models.Bid, error) {
+	if len(revealed) == 0 {
+		return nil, nil
+	}
+
+	winner := revealed[0]
+	if winner.Amount == nil {
+		return nil, fmt.Errorf("revealed sealed bid %s has no amount", winner.ID)
+	}
+
+	if auction.ReservePrice != nil && *winner.Amount < *auction.ReservePrice {
+		return nil, nil
+	}
+
+	price := *winner.Amount
+	if auction.SealedPricing == models.SealedPricingSecondPrice {
+		price = auction.StartPrice
+		if auction.ReservePrice != nil && *auction.ReservePrice > price {
+			price = *auction.ReservePrice
+		}
+		if len(revealed) > 1 && revealed[1].Amount != nil && *revealed[1].Amount > price {
+			price = *revealed[1].Amount
+		}
+	}
+
+	// Sealed bids are not signed, the bid history shows the opened commitment
+	message := sealedBidMessage(auction, winner, price)
+
+	return &models.Bid{
+		AuctionID: auction.ID,
+		BidderID:  winner.BidderID,
+		WalletID:  winner.WalletID,
+		Amount:    price,
+		Message:   &message,
+	}, nil
+}
+
+// sealedBidMessage records the revealed amount and commitment a sealed-bid
+// auction was won with, so the price can be checked against the commitment
+// the bidder made before bidding closed.
+func sealedBidMessage(auction *models.Auction, winner models.BidCommitment, price int64) string {
+	return fmt.Sprintf("Satonic sealed bid\n"+
+		"\n"+
+		"Auction: %s\n"+
+		"Revealed Amount: %d sats\n"+
+		"Commitment: %s\n"+
+		"Pricing: %s\n"+
+		"Price: %d sats",
+		auction.ID, *winner.Amount, winner.Commitment, auction.SealedPricing, price)
+}
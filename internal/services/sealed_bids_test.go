@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+func TestSealedWinner(t *testing.T) {
+	revealed := func(amounts ...int64) []models.BidCommitment {
+		commitments := make([]models.BidCommitment, len(amounts))
+		for i, amount := range amounts {
+			commitments[i] = models.BidCommitment{
+				ID:         fmt.Sprintf("commitment-%d", i),
+				BidderID:   fmt.Sprintf("bidder-%d", i),
+				WalletID:   fmt.Sprintf("wallet-%d", i),
+				Commitment: CommitmentHash(amount, "0123456789abcdef"),
+				Amount:     &amount,
+				Status:     models.CommitmentStatusRevealed,
+			}
+		}
+		return commitments
+	}
+	reserve := int64(30_000)
+
+	tests := []struct {
+		name     string
+		pricing  models.SealedPricing
+		reserve  *int64
+		revealed []models.BidCommitment
+		price    int64
+		noWinner bool
+	}{
+		{name: "first price", pricing: models.SealedPricingFirstPrice, revealed: revealed(50_000, 40_000), price: 50_000},
+		{name: "second price", pricing: models.SealedPricingSecondPrice, revealed: revealed(50_000, 40_000), price: 40_000},
+		{name: "second price single bid", pricing: models.SealedPricingSecondPrice, revealed: revealed(50_000), price: 10_000},
+		{name: "second price reserve", pricing: models.SealedPricingSecondPrice, reserve: &reserve, revealed: revealed(50_000, 20_000), price: 30_000},
+		{name: "below reserve", pricing: models.SealedPricingFirstPrice, reserve: &reserve, revealed: revealed(20_000), noWinner: true},
+		{name: "no reveals", pricing: models.SealedPricingFirstPrice, noWinner: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &models.Auction{
+				ID:            "auction",
+				Type:          models.AuctionTypeSealed,
+				StartPrice:    10_000,
+				ReservePrice:  tt.reserve,
+				SealedPricing: tt.pricing,
+			}
+
+			bid, err := sealedWinner(auction, tt.revealed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.noWinner {
+				if bid != nil {
+					t.Errorf("got a winning bid of %d sats, want none", bid.Amount)
+				}
+				return
+			}
+
+			if bid == nil {
+				t.Fatal("got no winning bid")
+			}
+			if bid.Amount != tt.price {
+				t.Errorf("got price %d, want %d", bid.Amount, tt.price)
+			}
+
+			// The bid history shows what the winner committed to
+			winner := tt.revealed[0]
+			if bid.BidderID != winner.BidderID || bid.WalletID != winner.WalletID {
+				t.Errorf("got bidder %s, want %s", bid.BidderID, winner.BidderID)
+			}
+			if bid.Message == nil {
+				t.Fatal("winning bid has no message")
+			}
+			for _, line := range []string{
+				"Commitment: " + winner.Commitment,
+				"Revealed Amount: 50000 sats",
+				"Pricing: " + string(tt.pricing),
+			} {
+				if !strings.Contains(*bid.Message, line) {
+					t.Errorf("message %q does not contain %q", *bid.Message, line)
+				}
+			}
+		})
+	}
+}
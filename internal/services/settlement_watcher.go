@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/satonic/satonic-api/internal/config"
+)
+
+// SettlementWatcher polls the chain backend for the confirmations of
+// settlement transactions
+type SettlementWatcher struct {
+	auctionService *AuctionService
+	confirmations  int
+	interval       time.Duration
+}
+
+// NewSettlementWatcher creates a new SettlementWatcher
+func NewSettlementWatcher(auctionService *AuctionService, cfg config.BitcoinConfig) *SettlementWatcher {
+	confirmations := cfg.SettlementConfirmations
+	if confirmations <= 0 {
+		confirmations = 1
+	}
+
+	interval := time.Duration(cfg.SettlementPollInterval) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	return &SettlementWatcher{
+		auctionService: auctionService,
+		confirmations:  confirmations,
+		interval:       interval,
+	}
+}
+
+// Run checks settling auctions on every tick until the context is cancelled
+func (w *SettlementWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.auctionService.CheckSettlements(w.confirmations); err != nil {
+				log.Printf("error checking settlements: %v", err)
+			}
+		}
+	}
+}
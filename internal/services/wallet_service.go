@@ -1,163 +1,1271 @@
 package services
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"math"
+	"sort"
+	"time"
 
-	"github.com/btcsuite/btcd/btcec/v2"
-	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/satonic/satonic-api/internal/chain"
+	"github.com/satonic/satonic-api/internal/config"
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+const (
+	// bitcoinMessagePrefix is prepended to messages signed with BIP-137
+	bitcoinMessagePrefix = "Bitcoin Signed Message:\n"
+
+	// bip322MessageTag is the tag used to hash BIP-322 messages
+	bip322MessageTag = "BIP0322-signed-message"
 )
 
 // WalletService handles wallet operations
-type WalletService struct{}
+type WalletService struct {
+	params *chaincfg.Params
+	chain  chain.ChainBackend
+}
+
+// NewWalletService creates a new WalletService for the configured network
+func NewWalletService(cfg config.BitcoinConfig, chainBackend chain.ChainBackend) *WalletService {
+	params, err := cfg.ChainParams()
+	if err != nil {
+		// The network is validated when the config is loaded
+		params = &chaincfg.MainNetParams
+	}
 
-// NewWalletService creates a new WalletService
-func NewWalletService() *WalletService {
-	return &WalletService{}
+	return &WalletService{
+		params: params,
+		chain:  chainBackend,
+	}
 }
 
-// VerifySignature verifies a signature for a message with a Bitcoin address
+// VerifySignature verifies a signature for a message with a Bitcoin address.
+// The signature must be base64 encoded and may either be a BIP-137 compact
+// signature or a BIP-322 simple or full signature.
 func (s *WalletService) VerifySignature(address, message, signature string) (bool, error) {
 	// Parse the signature
-	sigBytes, err := hex.DecodeString(signature)
+	sigBytes, err := base64.StdEncoding.DecodeString(signature)
 	if err != nil {
 		return false, fmt.Errorf("invalid signature format: %w", err)
 	}
 
-	// Taproot/Schnorr signature
-	if len(sigBytes) == 64 {
-		return s.verifySchnorrSignature(address, message, sigBytes)
+	// Decode the address the signature is claimed to be made by
+	addr, err := s.decodeAddress(address)
+	if err != nil {
+		return false, err
+	}
+
+	// BIP-137 compact signature (legacy "Bitcoin Signed Message")
+	if len(sigBytes) == 65 && sigBytes[0] >= 27 && sigBytes[0] <= 42 {
+		return s.verifyBIP137Signature(addr, message, sigBytes)
 	}
 
-	// ECDSA signature
-	return s.verifyECDSASignature(address, message, sigBytes)
+	// BIP-322 simple or full signature
+	return s.verifyBIP322Signature(addr, message, sigBytes)
 }
 
-// verifyECDSASignature verifies an ECDSA signature (for legacy and SegWit addresses)
-func (s *WalletService) verifyECDSASignature(address, message string, sigBytes []byte) (bool, error) {
-	// For a real implementation, you would:
-	// 1. Parse the signature (which includes recovery ID)
-	// 2. Hash the message (with Bitcoin message prefix)
-	// 3. Recover the public key from signature and message hash
-	// 4. Derive the address from the public key
-	// 5. Compare with the provided address
+// verifyBIP137Signature verifies a compact recoverable ECDSA signature (for
+// P2PKH, P2SH-P2WPKH and P2WPKH addresses)
+func (s *WalletService) verifyBIP137Signature(addr btcutil.Address, message string, sigBytes []byte) (bool, error) {
+	// Headers 35-42 mark SegWit addresses, which always use compressed keys.
+	// Normalise them to the compressed P2PKH range understood by RecoverCompact.
+	sig := make([]byte, len(sigBytes))
+	copy(sig, sigBytes)
+	if sig[0] >= 35 {
+		sig[0] = 31 + (sig[0]-27)&3
+	}
 
-	// This is a simplified placeholder - in production you'd use a proper Bitcoin library
-	// to handle the complex Bitcoin message signature format
+	// Recover the public key from the signature and message hash
+	pubKey, compressed, err := ecdsa.RecoverCompact(sig, bip137MessageHash(message))
+	if err != nil {
+		return false, nil
+	}
 
-	// TODO: Implement proper ECDSA signature verification
+	var serialized []byte
+	if compressed {
+		serialized = pubKey.SerializeCompressed()
+	} else {
+		serialized = pubKey.SerializeUncompressed()
+	}
 
-	return true, nil
+	// Derive every address type the key can sign for and compare with the
+	// provided one. Wallets disagree on which header to use for SegWit, so the
+	// header is not trusted to determine the address type.
+	candidates, err := s.addressesForPubKey(serialized, compressed)
+	if err != nil {
+		return false, err
+	}
+
+	for _, candidate := range candidates {
+		if candidate.EncodeAddress() == addr.EncodeAddress() {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
-// verifySchnorrSignature verifies a Schnorr signature (for Taproot addresses)
-func (s *WalletService) verifySchnorrSignature(address, message string, sigBytes []byte) (bool, error) {
-	// For a real implementation, you would:
-	// 1. Hash the message (with Bitcoin message prefix)
-	// 2. Parse the Schnorr signature
-	// 3. Extract the public key from the Taproot address
-	// 4. Verify the signature against the message hash and public key
+// addressesForPubKey returns the single-key addresses that can be derived from
+// a serialized public key
+func (s *WalletService) addressesForPubKey(serialized []byte, compressed bool) ([]btcutil.Address, error) {
+	keyHash := btcutil.Hash160(serialized)
+
+	p2pkh, err := btcutil.NewAddressPubKeyHash(keyHash, s.params)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create message hash
-	msgHash := chainhash.HashB([]byte(message))
+	// Uncompressed keys can only be used with legacy addresses
+	if !compressed {
+		return []btcutil.Address{p2pkh}, nil
+	}
 
-	// Parse signature (in reality, you'd extract from Bitcoin's signature format)
-	sig, err := schnorr.ParseSignature(sigBytes)
+	p2wpkh, err := btcutil.NewAddressWitnessPubKeyHash(keyHash, s.params)
 	if err != nil {
-		return false, fmt.Errorf("failed to parse Schnorr signature: %w", err)
+		return nil, err
 	}
 
-	// In a real implementation, you'd extract the public key from the address
-	// This is a placeholder - you'd need to properly derive the public key from the address
-	pubKeyHex := "03828271cfea9a138a5ba7535163daebb315b0a863b7fdb103f9b48f9e7e7d505d" // Example key
-	pubKeyBytes, err := hex.DecodeString(pubKeyHex)
+	redeemScript, err := txscript.PayToAddrScript(p2wpkh)
 	if err != nil {
-		return false, fmt.Errorf("invalid public key: %w", err)
+		return nil, err
+	}
+
+	p2shP2wpkh, err := btcutil.NewAddressScriptHash(redeemScript, s.params)
+	if err != nil {
+		return nil, err
+	}
+
+	return []btcutil.Address{p2pkh, p2shP2wpkh, p2wpkh}, nil
+}
+
+// verifyBIP322Signature verifies a BIP-322 signature (for P2WPKH, P2TR and any
+// other script the script engine can validate)
+func (s *WalletService) verifyBIP322Signature(addr btcutil.Address, message string, sigBytes []byte) (bool, error) {
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return false, fmt.Errorf("unsupported address: %w", err)
+	}
+
+	toSpend := bip322ToSpend(pkScript, message)
+	toSpendHash := toSpend.TxHash()
+
+	// A full signature is the complete to_sign transaction, a simple one is
+	// only the witness of its first input
+	toSign, err := parseBIP322Full(sigBytes, toSpendHash)
+	if err != nil {
+		witness, err := parseWitness(sigBytes)
+		if err != nil {
+			return false, fmt.Errorf("invalid BIP-322 signature: %w", err)
+		}
+		toSign = bip322ToSign(toSpendHash, witness)
+	}
+
+	// Run the to_sign input through the script engine against the to_spend output
+	prevOutFetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	sigHashes := txscript.NewTxSigHashes(toSign, prevOutFetcher)
+
+	engine, err := txscript.NewEngine(
+		pkScript, toSign, 0, txscript.StandardVerifyFlags, nil, sigHashes, 0, prevOutFetcher,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create script engine: %w", err)
+	}
+
+	if err := engine.Execute(); err != nil {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// bip137MessageHash returns the double SHA-256 of a message with the Bitcoin
+// signed message prefix
+func bip137MessageHash(message string) []byte {
+	var buf bytes.Buffer
+	wire.WriteVarString(&buf, 0, bitcoinMessagePrefix)
+	wire.WriteVarString(&buf, 0, message)
+	return chainhash.DoubleHashB(buf.Bytes())
+}
+
+// bip322ToSpend builds the virtual to_spend transaction for a message
+func bip322ToSpend(pkScript []byte, message string) *wire.MsgTx {
+	msgHash := chainhash.TaggedHash([]byte(bip322MessageTag), []byte(message))
+
+	scriptSig, _ := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).
+		AddData(msgHash[:]).
+		Script()
+
+	tx := wire.NewMsgTx(0)
+	prevOut := wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex)
+	txIn := wire.NewTxIn(prevOut, scriptSig, nil)
+	txIn.Sequence = 0
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(0, pkScript))
+
+	return tx
+}
+
+// bip322ToSign builds the virtual to_sign transaction for a simple signature
+func bip322ToSign(toSpendHash chainhash.Hash, witness wire.TxWitness) *wire.MsgTx {
+	tx := wire.NewMsgTx(0)
+	txIn := wire.NewTxIn(wire.NewOutPoint(&toSpendHash, 0), nil, witness)
+	txIn.Sequence = 0
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+
+	return tx
+}
+
+// parseBIP322Full parses a full BIP-322 signature, which must be a to_sign
+// transaction spending the to_spend output
+func parseBIP322Full(sigBytes []byte, toSpendHash chainhash.Hash) (*wire.MsgTx, error) {
+	tx := wire.NewMsgTx(0)
+	reader := bytes.NewReader(sigBytes)
+	if err := tx.Deserialize(reader); err != nil {
+		return nil, err
 	}
 
-	pubKey, err := btcec.ParsePubKey(pubKeyBytes)
+	if reader.Len() != 0 {
+		return nil, fmt.Errorf("trailing data after transaction")
+	}
+
+	if len(tx.TxIn) == 0 || tx.TxIn[0].PreviousOutPoint.Hash != toSpendHash ||
+		tx.TxIn[0].PreviousOutPoint.Index != 0 {
+		return nil, fmt.Errorf("transaction does not spend to_spend")
+	}
+
+	if len(tx.TxOut) != 1 || !bytes.Equal(tx.TxOut[0].PkScript, []byte{txscript.OP_RETURN}) {
+		return nil, fmt.Errorf("transaction must have a single OP_RETURN output")
+	}
+
+	return tx, nil
+}
+
+// parseWitness parses a consensus serialized witness stack
+func parseWitness(b []byte) (wire.TxWitness, error) {
+	reader := bytes.NewReader(b)
+
+	count, err := wire.ReadVarInt(reader, 0)
 	if err != nil {
-		return false, fmt.Errorf("failed to parse public key: %w", err)
+		return nil, err
+	}
+
+	if count == 0 || count > uint64(len(b)) {
+		return nil, fmt.Errorf("invalid witness item count %d", count)
+	}
+
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(reader, 0, uint32(len(b)), "witness item")
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Verify the signature
-	// In a real implementation, we'd compare the derived address with the provided one
-	return sig.Verify(msgHash, pubKey), nil
+	if reader.Len() != 0 {
+		return nil, fmt.Errorf("trailing data after witness")
+	}
+
+	return witness, nil
 }
 
-// GenerateMessageToSign generates a message for wallet signature
-func (s *WalletService) GenerateMessageToSign(address string) string {
-	// Generate a message that includes the address and a timestamp
-	// This helps prevent replay attacks
-	message := fmt.Sprintf("Sign this message to authenticate with Satonic: %s", address)
-	return message
+// GenerateMessageToSign generates a Sign-In-With-Bitcoin style message for a
+// wallet signature. The nonce and validity window make every message unique so
+// a captured signature cannot be replayed.
+func (s *WalletService) GenerateMessageToSign(domain, address, nonce string, issuedAt, expiresAt time.Time) string {
+	return fmt.Sprintf("%s wants you to sign in with your Bitcoin account:\n"+
+		"%s\n"+
+		"\n"+
+		"Sign this message to authenticate with Satonic.\n"+
+		"\n"+
+		"Chain ID: %s\n"+
+		"Nonce: %s\n"+
+		"Issued At: %s\n"+
+		"Expiration Time: %s",
+		domain, address, s.ChainID(), nonce,
+		issuedAt.UTC().Format(time.RFC3339), expiresAt.UTC().Format(time.RFC3339))
 }
 
-// ParsePSBT parses a Partially Signed Bitcoin Transaction
-func (s *WalletService) ParsePSBT(psbtHex string) (map[string]interface{}, error) {
-	// In a real implementation, you would parse the PSBT using a Bitcoin library
-	// This is a placeholder
+// GenerateBidMessage generates the canonical message a wallet signs to place
+// a bid, with its proxy maximum if it has one. The nonce and timestamp make
+// every message unique.
+func (s *WalletService) GenerateBidMessage(auctionID, address, nonce string, amount int64, maxAmount *int64, timestamp time.Time) string {
+	amounts := fmt.Sprintf("Amount: %d sats\n", amount)
+	if maxAmount != nil {
+		amounts += fmt.Sprintf("Max Amount: %d sats\n", *maxAmount)
+	}
+
+	return fmt.Sprintf("Satonic bid\n"+
+		"\n"+
+		"Auction: %s\n"+
+		"%s"+
+		"Wallet: %s\n"+
+		"Chain ID: %s\n"+
+		"Nonce: %s\n"+
+		"Timestamp: %s",
+		auctionID, amounts, address, s.ChainID(), nonce, timestamp.UTC().Format(time.RFC3339))
+}
 
-	// TODO: Implement proper PSBT parsing
+// ChainID returns the identifier of the Bitcoin network the service is using
+func (s *WalletService) ChainID() string {
+	return s.params.Name
+}
 
-	result := map[string]interface{}{
-		"valid": true,
-		"inputs": []map[string]interface{}{
-			{
-				"outpoint": "txid:vout",
-				"amount":   1000000, // in satoshis
-			},
-		},
-		"outputs": []map[string]interface{}{
-			{
-				"address": "bc1...",
-				"amount":  900000, // in satoshis
-			},
-			{
-				"address": "bc1...", // change address
-				"amount":  99000,    // in satoshis
-			},
-		},
-		"fee": 1000, // in satoshis
+// ParsePSBT parses a base64 or hex encoded Partially Signed Bitcoin Transaction
+func (s *WalletService) ParsePSBT(encoded string) (*models.PSBT, error) {
+	packet, err := decodePSBT(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PSBT: %w", err)
+	}
+
+	tx := packet.UnsignedTx
+	result := &models.PSBT{
+		TxID:     tx.TxHash().String(),
+		Version:  tx.Version,
+		LockTime: tx.LockTime,
+		Inputs:   make([]models.PSBTInput, len(tx.TxIn)),
+		Outputs:  make([]models.PSBTOutput, len(tx.TxOut)),
+	}
+
+	// Parse inputs, summing the spent amounts when they are known
+	var totalIn int64
+	allKnown := true
+	for i, txIn := range tx.TxIn {
+		in := &packet.Inputs[i]
+		input := models.PSBTInput{
+			TxID:        txIn.PreviousOutPoint.Hash.String(),
+			Vout:        txIn.PreviousOutPoint.Index,
+			Sequence:    txIn.Sequence,
+			SighashType: uint32(in.SighashType),
+			Signed:      isInputSigned(in),
+			Finalized:   len(in.FinalScriptWitness) > 0 || len(in.FinalScriptSig) > 0,
+		}
+
+		if utxo := inputUTXO(packet, i); utxo != nil {
+			input.WitnessUTXO = s.toPSBTOutput(utxo)
+			totalIn += utxo.Value
+		} else {
+			allKnown = false
+		}
+
+		result.Inputs[i] = input
+	}
+
+	// Parse outputs
+	var totalOut int64
+	for i, txOut := range tx.TxOut {
+		result.Outputs[i] = *s.toPSBTOutput(txOut)
+		totalOut += txOut.Value
+	}
+
+	if allKnown {
+		fee := totalIn - totalOut
+		result.Fee = &fee
 	}
 
 	return result, nil
 }
 
-// ValidatePSBT validates a Partially Signed Bitcoin Transaction for an NFT transfer
-func (s *WalletService) ValidatePSBT(psbtHex, inscriptionID string, sellerAddress, buyerAddress string) (bool, string, error) {
-	// In a real implementation, you would:
-	// 1. Parse the PSBT
-	// 2. Verify it contains the correct inscription output
-	// 3. Verify amounts, fees, etc.
+// ValidatePSBT validates a seller's listing PSBT for an inscription transfer.
+// The input spending the inscription must be signed with
+// SIGHASH_SINGLE|ANYONECANPAY and its paired output must pay the seller the
+// listing price. Lots have an input per inscription and their paired outputs
+// must pay the listing price in total. A *PSBTValidationError is returned if
+// the PSBT is rejected.
+func (s *WalletService) ValidatePSBT(encoded string, params ListingPSBTParams) error {
+	packet, err := decodePSBT(encoded)
+	if err != nil {
+		return &PSBTValidationError{
+			Code:    PSBTErrInvalidEncoding,
+			Message: fmt.Sprintf("failed to decode PSBT: %v", err),
+		}
+	}
+
+	sellerAddr, err := s.decodeAddress(params.SellerAddress)
+	if err != nil {
+		return &PSBTValidationError{
+			Code:    PSBTErrInvalidSellerAddr,
+			Message: fmt.Sprintf("invalid seller address: %v", err),
+		}
+	}
+	sellerScript, err := txscript.PayToAddrScript(sellerAddr)
+	if err != nil {
+		return &PSBTValidationError{
+			Code:    PSBTErrInvalidSellerAddr,
+			Message: fmt.Sprintf("unsupported seller address: %v", err),
+		}
+	}
+
+	inscriptions := append([]ListedInscription{{
+		InscriptionID: params.InscriptionID,
+		Location:      params.Location,
+	}}, params.Lot...)
+
+	// Every inscription needs its own signed input and payment
+	var index int
+	var total int64
+	spent := make(map[int]string)
+	for _, inscription := range inscriptions {
+		index, err = s.validateListingInput(packet, inscription, sellerScript)
+		if err != nil {
+			return err
+		}
+
+		if other, ok := spent[index]; ok {
+			return newPSBTError(PSBTErrInscriptionInput, index,
+				"inscriptions %s and %s are in the same UTXO", other, inscription.InscriptionID)
+		}
+		spent[index] = inscription.InscriptionID
+
+		total += packet.UnsignedTx.TxOut[index].Value
+	}
+
+	if total != params.Price {
+		if len(inscriptions) == 1 {
+			return newPSBTError(PSBTErrInvalidPayment, index,
+				"paired output pays %d sats, expected %d", total, params.Price)
+		}
+
+		return &PSBTValidationError{
+			Code:    PSBTErrInvalidPayment,
+			Message: fmt.Sprintf("paired outputs pay %d sats in total, expected %d", total, params.Price),
+		}
+	}
+
+	return nil
+}
+
+// validateListingInput checks the input of a listing PSBT spending an
+// inscription and the output paired with it. It returns the input's index.
+func (s *WalletService) validateListingInput(packet *psbt.Packet, inscription ListedInscription, sellerScript []byte) (int, error) {
+	// Locate the inscription
+	outPoint, offset, err := parseSatpoint(inscription.Location)
+	if err != nil {
+		return 0, &PSBTValidationError{
+			Code:    PSBTErrInvalidLocation,
+			Message: fmt.Sprintf("unknown location for inscription %s: %v", inscription.InscriptionID, err),
+		}
+	}
+
+	// Find the input spending the inscription UTXO
+	index := findInput(packet, outPoint)
+	if index < 0 {
+		return 0, &PSBTValidationError{
+			Code:    PSBTErrInscriptionInput,
+			Message: fmt.Sprintf("no input spends %s holding inscription %s", outPoint, inscription.InscriptionID),
+		}
+	}
+
+	// The inscription must be inside the spent UTXO, which must belong to the seller
+	utxo := inputUTXO(packet, index)
+	if utxo == nil {
+		return 0, newPSBTError(PSBTErrMissingUTXO, index, "witness UTXO is missing")
+	}
+
+	if !bytes.Equal(utxo.PkScript, sellerScript) {
+		return 0, newPSBTError(PSBTErrInputNotOwned, index, "UTXO is not owned by the seller address")
+	}
+
+	if offset >= utxo.Value {
+		return 0, newPSBTError(PSBTErrInvalidOffset, index,
+			"inscription offset %d is outside the %d sat UTXO", offset, utxo.Value)
+	}
+
+	// The seller must have signed the input with SIGHASH_SINGLE|ANYONECANPAY
+	if !isInputSigned(&packet.Inputs[index]) {
+		return 0, newPSBTError(PSBTErrNotSigned, index, "input is not signed")
+	}
+
+	sigHashType, err := s.verifyInputSignature(packet, index)
+	if err != nil {
+		return 0, newPSBTError(PSBTErrInvalidSignature, index, "signature verification failed: %v", err)
+	}
+
+	if sigHashType != listingSighash {
+		return 0, newPSBTError(PSBTErrInvalidSighash, index,
+			"input must be signed with SIGHASH_SINGLE|ANYONECANPAY, got 0x%02x", uint32(sigHashType))
+	}
+
+	// The paired output must pay the seller
+	if index >= len(packet.UnsignedTx.TxOut) {
+		return 0, newPSBTError(PSBTErrPaymentMissing, index, "no output paired with the inscription input")
+	}
+
+	payment := packet.UnsignedTx.TxOut[index]
+	if !bytes.Equal(payment.PkScript, sellerScript) {
+		return 0, newPSBTError(PSBTErrInvalidPayment, index, "paired output does not pay the seller")
+	}
+
+	return index, nil
+}
+
+// PurchasePSBTParams describes the purchase transaction to build around a
+// seller's listing
+type PurchasePSBTParams struct {
+	ListingPSBT    string
+	Location       string   // satpoint of the inscription, txid:vout:offset
+	Lot            []string // satpoints of further inscriptions sold as a lot
+	BuyerAddress   string   // funds the purchase and receives change
+	ReceiveAddress string   // receives the inscription, defaults to BuyerAddress
+	Price          int64    // total paid for the inscriptions, in satoshis
+	Inscribed      []string // buyer outpoints holding inscriptions, never spent
+	Payouts        []Payout // fees paid out of the price
+}
+
+// Payout is a payment to a third party, such as a royalty, taken out of the
+// price of a sale
+type Payout struct {
+	Address string
+	Amount  int64
+}
+
+// BuildPurchasePSBT combines a seller's signed listing inputs with funding
+// inputs from the buyer's wallet. The smallest buyer UTXO is spent first so
+// that the inscriptions land in the first output, which pays the buyer; the
+// seller's inputs and their paired payment outputs keep the same index.
+func (s *WalletService) BuildPurchasePSBT(params PurchasePSBTParams) (*models.SettlementPSBT, error) {
+	sellers, err := listingSellers(params.ListingPSBT, append([]string{params.Location}, params.Lot...))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildTransferPSBT(sellers, params)
+}
+
+// ListingProceeds returns what a listing PSBT pays the seller for the
+// inscriptions at the given satpoints
+func (s *WalletService) ListingProceeds(listingPSBT string, locations []string) (int64, error) {
+	sellers, err := listingSellers(listingPSBT, locations)
+	if err != nil {
+		return 0, err
+	}
+
+	var proceeds int64
+	for _, seller := range sellers {
+		proceeds += seller.payment.Value
+	}
+
+	return proceeds, nil
+}
+
+// listingSellers takes the seller's signed inputs spending the inscriptions
+// at the given satpoints from a listing PSBT
+func listingSellers(listingPSBT string, locations []string) ([]sellerInput, error) {
+	listing, err := decodePSBT(listingPSBT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode listing PSBT: %w", err)
+	}
+
+	var sellers []sellerInput
+	for _, location := range locations {
+		outPoint, _, err := parseSatpoint(location)
+		if err != nil {
+			return nil, err
+		}
+
+		// Take the seller's signed input and the payment output it commits to
+		sellerIndex := findInput(listing, outPoint)
+		if sellerIndex < 0 || sellerIndex >= len(listing.UnsignedTx.TxOut) {
+			return nil, fmt.Errorf("listing PSBT does not spend the inscription at %s", location)
+		}
+
+		sellerUTXO := inputUTXO(listing, sellerIndex)
+		if sellerUTXO == nil {
+			return nil, fmt.Errorf("listing PSBT is missing the inscription UTXO at %s", location)
+		}
+
+		sellers = append(sellers, sellerInput{
+			txIn:     listing.UnsignedTx.TxIn[sellerIndex],
+			input:    listing.Inputs[sellerIndex],
+			utxo:     sellerUTXO,
+			payment:  listing.UnsignedTx.TxOut[sellerIndex],
+			version:  listing.UnsignedTx.Version,
+			lockTime: listing.UnsignedTx.LockTime,
+		})
+	}
+
+	return sellers, nil
+}
+
+// sellerInput is the seller's side of a transfer: the input spending the
+// inscription and the output paying the seller. Signatures of the seller's
+// input commit to the version and lock time of the transaction.
+type sellerInput struct {
+	txIn     *wire.TxIn
+	input    psbt.PInput
+	utxo     *wire.TxOut
+	payment  *wire.TxOut
+	version  int32
+	lockTime uint32
+}
+
+// buildTransferPSBT funds the transfer of inscriptions from the buyer's
+// wallet, with the seller's inputs and payments from index 1 after a leading
+// buyer input
+func (s *WalletService) buildTransferPSBT(sellers []sellerInput, params PurchasePSBTParams) (*models.SettlementPSBT, error) {
+	var listingPrice, sellerValue int64
+	for _, seller := range sellers {
+		listingPrice += seller.payment.Value
+		sellerValue += seller.utxo.Value
+
+		if seller.version != sellers[0].version || seller.lockTime != sellers[0].lockTime {
+			return nil, fmt.Errorf("listing PSBTs disagree on the transaction version or lock time")
+		}
+	}
+	if params.Price < listingPrice {
+		return nil, fmt.Errorf("price %d is below the listing price %d", params.Price, listingPrice)
+	}
+
+	var payouts []*wire.TxOut
+	var payoutTotal int64
+	for _, payout := range params.Payouts {
+		addr, err := s.decodeAddress(payout.Address)
+		if err != nil {
+			return nil, err
+		}
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, wire.NewTxOut(payout.Amount, script))
+		payoutTotal += payout.Amount
+	}
+
+	extraPayment := params.Price - listingPrice - payoutTotal
+	if extraPayment < 0 {
+		return nil, fmt.Errorf("price %d does not cover the listing price %d and %d sats of fees",
+			params.Price, listingPrice, payoutTotal)
+	}
+	sellerPayment := sellers[0].payment
+
+	// Resolve the buyer's scripts
+	buyerAddr, err := s.decodeAddress(params.BuyerAddress)
+	if err != nil {
+		return nil, err
+	}
+	buyerScript, err := txscript.PayToAddrScript(buyerAddr)
+	if err != nil {
+		return nil, err
+	}
+	if !txscript.IsWitnessProgram(buyerScript) {
+		return nil, fmt.Errorf("funding wallet must be a native SegWit or Taproot address")
+	}
+
+	receiveScript := buyerScript
+	if params.ReceiveAddress != "" {
+		receiveAddr, err := s.decodeAddress(params.ReceiveAddress)
+		if err != nil {
+			return nil, err
+		}
+		receiveScript, err = txscript.PayToAddrScript(receiveAddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	feeRate, err := s.EstimateFeeRate()
+	if err != nil {
+		return nil, err
+	}
+
+	// Gather the buyer's UTXOs, smallest first
+	utxos, err := s.chain.GetUTXOs(params.BuyerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UTXOs: %w", err)
+	}
+	utxos = spendableUTXOs(utxos, params.Inscribed)
+	sort.Slice(utxos, func(i, j int) bool {
+		return utxos[i].Value < utxos[j].Value
+	})
+
+	if len(utxos) < 2 {
+		return nil, fmt.Errorf("buyer wallet needs at least two UTXOs to settle")
+	}
+
+	// The smallest UTXO leads the transaction, the largest fund it
+	leading := utxos[0]
+	candidates := utxos[1:]
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Value > candidates[j].Value
+	})
+
+	vsize := int64(txOverheadVSize) + inputVSize(buyerScript) + outputVSize(receiveScript) + outputVSize(buyerScript)
+	for _, seller := range sellers {
+		vsize += inputVSize(seller.utxo.PkScript) + outputVSize(seller.payment.PkScript)
+	}
+	if extraPayment > 0 {
+		vsize += outputVSize(sellerPayment.PkScript)
+	}
+	for _, payout := range payouts {
+		vsize += outputVSize(payout.PkScript)
+	}
+
+	var funding []chain.UTXO
+	var totalFunding, fee int64
+	for _, utxo := range candidates {
+		funding = append(funding, utxo)
+		totalFunding += utxo.Value
+		vsize += inputVSize(buyerScript)
+		fee = int64(math.Ceil(float64(vsize) * feeRate))
+
+		if totalFunding >= params.Price+fee {
+			break
+		}
+	}
+
+	if totalFunding < params.Price+fee {
+		return nil, fmt.Errorf("insufficient funds: need %d sats, have %d", params.Price+fee, totalFunding)
+	}
+
+	// Change below the dust limit is left to the miners
+	change := totalFunding - params.Price - fee
+	if change < dustLimit {
+		fee += change
+		change = 0
+	}
+
+	// Assemble the transaction with the version and lock time the seller signed
+	tx := wire.NewMsgTx(sellers[0].version)
+	tx.LockTime = sellers[0].lockTime
+	buyerSequence := wire.MaxTxInSequenceNum - 2
+
+	leadingIn, err := utxoTxIn(leading, buyerSequence)
+	if err != nil {
+		return nil, err
+	}
+	tx.AddTxIn(leadingIn)
+
+	for _, seller := range sellers {
+		txIn := wire.NewTxIn(&seller.txIn.PreviousOutPoint, nil, nil)
+		txIn.Sequence = seller.txIn.Sequence
+		tx.AddTxIn(txIn)
+	}
+
+	for _, utxo := range funding {
+		txIn, err := utxoTxIn(utxo, buyerSequence)
+		if err != nil {
+			return nil, err
+		}
+		tx.AddTxIn(txIn)
+	}
+
+	tx.AddTxOut(wire.NewTxOut(leading.Value+sellerValue, receiveScript))
+	for _, seller := range sellers {
+		tx.AddTxOut(wire.NewTxOut(seller.payment.Value, seller.payment.PkScript))
+	}
+	if extraPayment > 0 {
+		tx.AddTxOut(wire.NewTxOut(extraPayment, sellerPayment.PkScript))
+	}
+	for _, payout := range payouts {
+		tx.AddTxOut(payout)
+	}
+	if change > 0 {
+		tx.AddTxOut(wire.NewTxOut(change, buyerScript))
+	}
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Carry over the seller's signed inputs and describe the buyer's inputs
+	for i, seller := range sellers {
+		packet.Inputs[i+1] = seller.input
+	}
+
+	inputsToSign := []int{0}
+	packet.Inputs[0].WitnessUtxo = wire.NewTxOut(leading.Value, buyerScript)
+	for i, utxo := range funding {
+		index := i + 1 + len(sellers)
+		packet.Inputs[index].WitnessUtxo = wire.NewTxOut(utxo.Value, buyerScript)
+		inputsToSign = append(inputsToSign, index)
+	}
+
+	encoded, err := packet.B64Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SettlementPSBT{
+		PSBT:         encoded,
+		TxID:         tx.TxHash().String(),
+		Fee:          fee,
+		InputsToSign: inputsToSign,
+	}, nil
+}
+
+// VerifyPayouts checks that a settlement PSBT pays each payout, so that a
+// PSBT prepared under other fees cannot be broadcast
+func (s *WalletService) VerifyPayouts(encoded string, payouts []Payout) error {
+	packet, err := decodePSBT(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to decode settlement PSBT: %w", err)
+	}
+
+	// Each output can only account for one payout
+	used := make(map[int]bool)
+	for _, payout := range payouts {
+		addr, err := s.decodeAddress(payout.Address)
+		if err != nil {
+			return err
+		}
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for i, txOut := range packet.UnsignedTx.TxOut {
+			if !used[i] && txOut.Value == payout.Amount && bytes.Equal(txOut.PkScript, script) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("settlement PSBT does not pay %d sats to %s", payout.Amount, payout.Address)
+		}
+	}
+
+	return nil
+}
+
+// FinalizePurchasePSBT merges the buyer's signatures into a prepared purchase
+// PSBT, verifies every input and extracts the raw transaction. It returns the
+// hex encoded transaction and its ID.
+func (s *WalletService) FinalizePurchasePSBT(prepared, signed string) (string, string, error) {
+	packet, err := decodePSBT(prepared)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode settlement PSBT: %w", err)
+	}
+
+	signedPacket, err := decodePSBT(signed)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode signed PSBT: %w", err)
+	}
+
+	if signedPacket.UnsignedTx.TxHash() != packet.UnsignedTx.TxHash() {
+		return "", "", fmt.Errorf("signed PSBT does not match the settlement PSBT")
+	}
+
+	// Merge the buyer's signatures and verify every input
+	for i := range packet.Inputs {
+		if !isInputSigned(&packet.Inputs[i]) {
+			copyInputSignatures(&packet.Inputs[i], &signedPacket.Inputs[i])
+		}
+
+		if !isInputSigned(&packet.Inputs[i]) {
+			return "", "", fmt.Errorf("input %d is not signed", i)
+		}
+
+		if _, err := s.verifyInputSignature(packet, i); err != nil {
+			return "", "", fmt.Errorf("input %d: signature verification failed: %w", i, err)
+		}
+	}
+
+	if err := psbt.MaybeFinalizeAll(packet); err != nil {
+		return "", "", fmt.Errorf("failed to finalize PSBT: %w", err)
+	}
+
+	tx, err := psbt.Extract(packet)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to extract transaction: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", "", err
+	}
+
+	return hex.EncodeToString(buf.Bytes()), tx.TxHash().String(), nil
+}
+
+// OfferPSBTParams describes the transfer an offer proposes to an NFT's owner
+type OfferPSBTParams struct {
+	Location       string   // satpoint of the inscription, txid:vout:offset
+	OwnerAddress   string   // holds the inscription and receives the payment
+	BuyerAddress   string   // funds the offer and receives change
+	ReceiveAddress string   // receives the inscription, defaults to BuyerAddress
+	Amount         int64    // total offered, in satoshis
+	Payouts        []Payout // fees paid out of the amount
+}
 
-	// This is a placeholder
-	return true, "PSBT is valid for NFT transfer", nil
+// BuildOfferPSBT builds the transaction of an offer. It has the same layout as
+// a purchase PSBT, except that the owner's inscription input is unsigned: the
+// offerer signs their inputs with SIGHASH_ALL, committing to the whole
+// transaction, and the owner signs the inscription input to accept.
+func (s *WalletService) BuildOfferPSBT(params OfferPSBTParams) (*models.SettlementPSBT, error) {
+	outPoint, offset, err := parseSatpoint(params.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerAddr, err := s.decodeAddress(params.OwnerAddress)
+	if err != nil {
+		return nil, err
+	}
+	ownerScript, err := txscript.PayToAddrScript(ownerAddr)
+	if err != nil {
+		return nil, err
+	}
+	if !txscript.IsWitnessProgram(ownerScript) {
+		return nil, fmt.Errorf("owner wallet must be a native SegWit or Taproot address")
+	}
+
+	// Look up the UTXO holding the inscription
+	tx, err := s.chain.GetTransaction(outPoint.Hash.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if tx == nil || int(outPoint.Index) >= len(tx.Vout) {
+		return nil, fmt.Errorf("inscription output %s not found", outPoint)
+	}
+
+	output := tx.Vout[outPoint.Index]
+	pkScript, err := hex.DecodeString(output.ScriptPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inscription output script: %w", err)
+	}
+
+	if !bytes.Equal(pkScript, ownerScript) {
+		return nil, fmt.Errorf("inscription is not held by the owner address")
+	}
+
+	if offset >= output.Value {
+		return nil, fmt.Errorf("inscription offset %d is outside the %d sat UTXO", offset, output.Value)
+	}
+
+	// The owner is paid what is left of the amount after fees
+	payment := params.Amount
+	for _, payout := range params.Payouts {
+		payment -= payout.Amount
+	}
+	if payment < dustLimit {
+		return nil, fmt.Errorf("offer of %d sats does not cover its fees", params.Amount)
+	}
+
+	utxo := wire.NewTxOut(output.Value, pkScript)
+	txIn := wire.NewTxIn(&outPoint, nil, nil)
+	txIn.Sequence = wire.MaxTxInSequenceNum - 2
+
+	return s.buildTransferPSBT([]sellerInput{{
+		txIn:    txIn,
+		input:   psbt.PInput{WitnessUtxo: utxo},
+		utxo:    utxo,
+		payment: wire.NewTxOut(payment, ownerScript),
+		version: 2,
+	}}, PurchasePSBTParams{
+		Location:       params.Location,
+		BuyerAddress:   params.BuyerAddress,
+		ReceiveAddress: params.ReceiveAddress,
+		Price:          params.Amount,
+		Payouts:        params.Payouts,
+	})
 }
 
-// GetBalance gets the balance of a wallet address
+// MergeOfferSignatures merges the offerer's signatures into a prepared offer
+// PSBT. Every input but the owner's inscription input must be signed with
+// SIGHASH_ALL so that the owner cannot change the offer. A
+// *PSBTValidationError is returned if the signed PSBT is rejected.
+func (s *WalletService) MergeOfferSignatures(prepared, signed, location string) (string, error) {
+	packet, err := decodePSBT(prepared)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode offer PSBT: %w", err)
+	}
+
+	signedPacket, err := decodePSBT(signed)
+	if err != nil {
+		return "", &PSBTValidationError{
+			Code:    PSBTErrInvalidEncoding,
+			Message: fmt.Sprintf("failed to decode PSBT: %v", err),
+		}
+	}
+
+	if signedPacket.UnsignedTx.TxHash() != packet.UnsignedTx.TxHash() {
+		return "", &PSBTValidationError{
+			Code:    PSBTErrTxMismatch,
+			Message: "signed PSBT does not match the offer PSBT",
+		}
+	}
+
+	outPoint, _, err := parseSatpoint(location)
+	if err != nil {
+		return "", err
+	}
+
+	ownerIndex := findInput(packet, outPoint)
+	if ownerIndex < 0 {
+		return "", fmt.Errorf("offer PSBT does not spend the inscription")
+	}
+
+	for i := range packet.Inputs {
+		if i == ownerIndex {
+			continue
+		}
+
+		copyInputSignatures(&packet.Inputs[i], &signedPacket.Inputs[i])
+		if !isInputSigned(&packet.Inputs[i]) {
+			return "", newPSBTError(PSBTErrNotSigned, i, "input is not signed")
+		}
+
+		sigHashType, err := s.verifyInputSignature(packet, i)
+		if err != nil {
+			return "", newPSBTError(PSBTErrInvalidSignature, i, "signature verification failed: %v", err)
+		}
+
+		if sigHashType != txscript.SigHashAll && sigHashType != txscript.SigHashDefault {
+			return "", newPSBTError(PSBTErrInvalidSighash, i,
+				"input must be signed with SIGHASH_ALL, got 0x%02x", uint32(sigHashType))
+		}
+	}
+
+	return packet.B64Encode()
+}
+
+// SpentInput returns the index of the first input of a PSBT whose outpoint has
+// already been spent, or -1 if every input is unspent
+func (s *WalletService) SpentInput(encoded string) (int, error) {
+	packet, err := decodePSBT(encoded)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode PSBT: %w", err)
+	}
+
+	for i, txIn := range packet.UnsignedTx.TxIn {
+		prevOut := txIn.PreviousOutPoint
+		outspend, err := s.chain.GetOutspend(prevOut.Hash.String(), prevOut.Index)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get outspend: %w", err)
+		}
+
+		if outspend.Spent {
+			return i, nil
+		}
+	}
+
+	return -1, nil
+}
+
+// BroadcastTransaction broadcasts a raw hex encoded transaction
+func (s *WalletService) BroadcastTransaction(rawTxHex string) (string, error) {
+	return s.chain.Broadcast(rawTxHex)
+}
+
+// SettlementStatus describes the on-chain state of a settlement transaction
+type SettlementStatus struct {
+	Confirmations int64
+	ConflictTxID  string // set when another transaction spent the inscription
+	Dropped       bool   // the transaction is unknown and the inscription unspent
+}
+
+// GetSettlementStatus checks the confirmations of a settlement transaction and
+// whether it was replaced or dropped from the mempool. The location is the
+// inscription's satpoint before settlement.
+func (s *WalletService) GetSettlementStatus(txid, location string) (*SettlementStatus, error) {
+	tx, err := s.chain.GetTransaction(txid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if tx != nil {
+		if !tx.Status.Confirmed {
+			return &SettlementStatus{}, nil
+		}
+
+		tipHeight, err := s.chain.GetTipHeight()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tip height: %w", err)
+		}
+
+		return &SettlementStatus{Confirmations: tipHeight - tx.Status.BlockHeight + 1}, nil
+	}
+
+	// The transaction is unknown, check what happened to the inscription
+	outPoint, _, err := parseSatpoint(location)
+	if err != nil {
+		return nil, err
+	}
+
+	outspend, err := s.chain.GetOutspend(outPoint.Hash.String(), outPoint.Index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outspend: %w", err)
+	}
+
+	if !outspend.Spent {
+		return &SettlementStatus{Dropped: true}, nil
+	}
+
+	if outspend.TxID != txid {
+		return &SettlementStatus{ConflictTxID: outspend.TxID}, nil
+	}
+
+	return &SettlementStatus{}, nil
+}
+
+// EstimateFeeRate returns the fee rate in sat/vB to use for settlement
+func (s *WalletService) EstimateFeeRate() (float64, error) {
+	estimates, err := s.chain.GetFeeEstimates()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get fee estimates: %w", err)
+	}
+
+	if rate, ok := estimates[settlementConfTarget]; ok {
+		return rate, nil
+	}
+
+	// Fall back to the closest slower target
+	bestTarget := 0
+	for target := range estimates {
+		if target >= settlementConfTarget && (bestTarget == 0 || target < bestTarget) {
+			bestTarget = target
+		}
+	}
+
+	if bestTarget == 0 {
+		return 1, nil
+	}
+
+	return estimates[bestTarget], nil
+}
+
+// GetBalance gets the balance of a wallet address from its unspent outputs
 func (s *WalletService) GetBalance(address string) (int64, error) {
-	// In a real implementation, you would query a Bitcoin node or service
-	// This is a placeholder
+	utxos, err := s.chain.GetUTXOs(address)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get UTXOs: %w", err)
+	}
+
+	var balance int64
+	for _, utxo := range utxos {
+		balance += utxo.Value
+	}
 
-	return 10000000, nil // 0.1 BTC in satoshis
+	return balance, nil
 }
 
-// IsAddressValid checks if a Bitcoin address is valid
-func (s *WalletService) IsAddressValid(address string) bool {
-	// In a real implementation, you would validate the address format
-	// This is a simplified check - not suitable for production
+// SettlementFunds returns how much a wallet can pay in a settlement: the value
+// of its UTXOs that hold no inscription, less the leading UTXO a settlement
+// starts with and an estimate of the fee of spending the others
+func (s *WalletService) SettlementFunds(address string, inscribed []string) (int64, error) {
+	addr, err := s.decodeAddress(address)
+	if err != nil {
+		return 0, err
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return 0, err
+	}
+
+	utxos, err := s.chain.GetUTXOs(address)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get UTXOs: %w", err)
+	}
+
+	utxos = spendableUTXOs(utxos, inscribed)
+	if len(utxos) < 2 {
+		return 0, nil
+	}
+
+	feeRate, err := s.EstimateFeeRate()
+	if err != nil {
+		return 0, err
+	}
 
-	// Check if address starts with common Bitcoin prefixes
-	// In production, use proper validation from a Bitcoin library
-	prefixes := []string{"1", "3", "bc1"}
+	// The smallest UTXO leads and goes to the buyer with the inscription
+	sort.Slice(utxos, func(i, j int) bool {
+		return utxos[i].Value < utxos[j].Value
+	})
 
-	for _, prefix := range prefixes {
-		if len(address) >= len(prefix) && address[:len(prefix)] == prefix {
-			return true
+	var funds int64
+	for _, utxo := range utxos[1:] {
+		funds += utxo.Value
+	}
+
+	// Assume the seller's input and payment are the size of the buyer's,
+	// next to the receive and change outputs
+	vsize := int64(txOverheadVSize) + int64(len(utxos)+1)*inputVSize(script) + 3*outputVSize(script)
+	funds -= int64(math.Ceil(float64(vsize) * feeRate))
+	if funds < 0 {
+		return 0, nil
+	}
+
+	return funds, nil
+}
+
+// spendableUTXOs leaves out the UTXOs at the given outpoints, txid:vout
+func spendableUTXOs(utxos []chain.UTXO, inscribed []string) []chain.UTXO {
+	if len(inscribed) == 0 {
+		return utxos
+	}
+
+	excluded := make(map[string]bool, len(inscribed))
+	for _, outpoint := range inscribed {
+		excluded[outpoint] = true
+	}
+
+	spendable := make([]chain.UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		if !excluded[fmt.Sprintf("%s:%d", utxo.TxID, utxo.Vout)] {
+			spendable = append(spendable, utxo)
 		}
 	}
 
-	return false
+	return spendable
+}
+
+// IsAddressValid checks if a Bitcoin address is valid for the configured network
+func (s *WalletService) IsAddressValid(address string) bool {
+	_, err := s.decodeAddress(address)
+	return err == nil
+}
+
+// AddressType detects the script type of a Bitcoin address
+func (s *WalletService) AddressType(address string) (models.AddressType, error) {
+	addr, err := s.decodeAddress(address)
+	if err != nil {
+		return "", err
+	}
+
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return models.AddressTypeP2PKH, nil
+	case *btcutil.AddressScriptHash:
+		return models.AddressTypeP2SH, nil
+	case *btcutil.AddressWitnessPubKeyHash:
+		return models.AddressTypeP2WPKH, nil
+	case *btcutil.AddressWitnessScriptHash:
+		return models.AddressTypeP2WSH, nil
+	case *btcutil.AddressTaproot:
+		return models.AddressTypeP2TR, nil
+	default:
+		return "", fmt.Errorf("unsupported address type")
+	}
+}
+
+// decodeAddress decodes a Base58Check, bech32 or bech32m address, verifying
+// its checksum and that it belongs to the configured network
+func (s *WalletService) decodeAddress(address string) (btcutil.Address, error) {
+	addr, err := btcutil.DecodeAddress(address, s.params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+
+	// Base58 addresses are decoded regardless of their network version byte
+	if !addr.IsForNet(s.params) {
+		return nil, fmt.Errorf("address is not valid for %s", s.params.Name)
+	}
+
+	return addr, nil
 }
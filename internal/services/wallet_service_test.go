@@ -0,0 +1,574 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/satonic/satonic-api/internal/chain"
+	"github.com/satonic/satonic-api/internal/config"
+)
+
+// BIP-322 test vectors, signed with L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k
+const (
+	bip322P2WPKHAddress = "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+	bip322P2TRAddress   = "bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3"
+
+	bip322P2WPKHEmpty      = "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="
+	bip322P2WPKHHelloWorld = "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI="
+	bip322P2WPKHHelloAlt   = "AkgwRQIhAOzyynlqt93lOKJr+wmmxIens//zPzl9tqIOua93wO6MAiBi5n5EyAcPScOjf1lAqIUIQtr3zKNeavYabHyR8eGhowEhAsfxIAMZZEKUPYWI4BruhAQjzFT8FSFSajuFwrDL1Yhy"
+	bip322P2TRHelloWorld   = "AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ=="
+)
+
+// BIP-137 test vectors from bitcoinjs-message, one key signing for each address type
+const (
+	bip137Message = "This is an example of a signed message."
+
+	bip137P2PKHAddress      = "1F3sAm6ZtwLAUnj7d38pGFxtP3RVEvtsbV"
+	bip137P2SHP2WPKHAddress = "3DnW8JGpPViEZdpqat8qky1zc26EKbXnmM"
+	bip137P2WPKHAddress     = "bc1qngw83fg8dz0k749cg7k3emc7v98wy0c74dlrkd"
+
+	bip137P2PKH      = "H9L5yLFjti0QTHhPyFrZCT1V/MMnBtXKmoiKDZ78NDBjERki6ZTQZdSMCtkgoNmp17By9ItJr8o7ChX0XxY91nk="
+	bip137P2SHP2WPKH = "I9L5yLFjti0QTHhPyFrZCT1V/MMnBtXKmoiKDZ78NDBjERki6ZTQZdSMCtkgoNmp17By9ItJr8o7ChX0XxY91nk="
+	bip137P2WPKH     = "J9L5yLFjti0QTHhPyFrZCT1V/MMnBtXKmoiKDZ78NDBjERki6ZTQZdSMCtkgoNmp17By9ItJr8o7ChX0XxY91nk="
+)
+
+func TestBIP322MessageHash(t *testing.T) {
+	tests := []struct {
+		message string
+		hash    string
+	}{
+		{"", "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1"},
+		{"Hello World", "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a"},
+	}
+
+	for _, tt := range tests {
+		hash := chainhash.TaggedHash([]byte(bip322MessageTag), []byte(tt.message))
+		if got := hex.EncodeToString(hash[:]); got != tt.hash {
+			t.Errorf("message %q: got hash %s, want %s", tt.message, got, tt.hash)
+		}
+	}
+}
+
+func TestBIP322Transactions(t *testing.T) {
+	s := NewWalletService(config.BitcoinConfig{}, nil)
+	addr, err := s.decodeAddress(bip322P2WPKHAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		toSpend string
+		toSign  string
+	}{
+		{"", "c5680aa69bb8d860bf82d4e9cd3504b55dde018de765a91bb566283c545a99a7",
+			"1e9654e951a5ba44c8604c4de6c67fd78a27e81dcadcfe1edf638ba3aaebaed6"},
+		{"Hello World", "b79d196740ad5217771c1098fc4a4b51e0535c32236c71f1ea4d61a2d603352b",
+			"88737ae86f2077145f93cc4b153ae9a1cb8d56afa511988c149c5c8c9d93bddf"},
+	}
+
+	for _, tt := range tests {
+		toSpendHash := bip322ToSpend(pkScript, tt.message).TxHash()
+		if got := toSpendHash.String(); got != tt.toSpend {
+			t.Errorf("message %q: got to_spend %s, want %s", tt.message, got, tt.toSpend)
+		}
+
+		if got := bip322ToSign(toSpendHash, nil).TxHash().String(); got != tt.toSign {
+			t.Errorf("message %q: got to_sign %s, want %s", tt.message, got, tt.toSign)
+		}
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	s := NewWalletService(config.BitcoinConfig{}, nil)
+
+	tests := []struct {
+		name      string
+		address   string
+		message   string
+		signature string
+		valid     bool
+	}{
+		{"BIP-322 simple P2WPKH empty message", bip322P2WPKHAddress, "", bip322P2WPKHEmpty, true},
+		{"BIP-322 simple P2WPKH", bip322P2WPKHAddress, "Hello World", bip322P2WPKHHelloWorld, true},
+		{"BIP-322 simple P2WPKH other nonce", bip322P2WPKHAddress, "Hello World", bip322P2WPKHHelloAlt, true},
+		{"BIP-322 simple P2WPKH wrong message", bip322P2WPKHAddress, "Hello World!", bip322P2WPKHHelloWorld, false},
+		{"BIP-322 simple P2WPKH message of other signature", bip322P2WPKHAddress, "", bip322P2WPKHHelloWorld, false},
+		{"BIP-322 simple P2TR", bip322P2TRAddress, "Hello World", bip322P2TRHelloWorld, true},
+		{"BIP-322 simple P2TR wrong message", bip322P2TRAddress, "Hello", bip322P2TRHelloWorld, false},
+		{"BIP-322 simple P2TR wrong address", bip322P2WPKHAddress, "Hello World", bip322P2TRHelloWorld, false},
+		{"BIP-322 full P2WPKH empty message", bip322P2WPKHAddress, "", bip322Full(t, s, bip322P2WPKHAddress, "", bip322P2WPKHEmpty), true},
+		{"BIP-322 full P2WPKH", bip322P2WPKHAddress, "Hello World", bip322Full(t, s, bip322P2WPKHAddress, "Hello World", bip322P2WPKHHelloWorld), true},
+		{"BIP-322 full P2WPKH wrong message", bip322P2WPKHAddress, "Hello World!", bip322Full(t, s, bip322P2WPKHAddress, "Hello World", bip322P2WPKHHelloWorld), false},
+		{"BIP-322 full P2TR", bip322P2TRAddress, "Hello World", bip322Full(t, s, bip322P2TRAddress, "Hello World", bip322P2TRHelloWorld), true},
+		{"BIP-322 full P2TR wrong message", bip322P2TRAddress, "Hello", bip322Full(t, s, bip322P2TRAddress, "Hello World", bip322P2TRHelloWorld), false},
+		{"BIP-137 P2PKH", bip137P2PKHAddress, bip137Message, bip137P2PKH, true},
+		{"BIP-137 P2SH-P2WPKH", bip137P2SHP2WPKHAddress, bip137Message, bip137P2SHP2WPKH, true},
+		{"BIP-137 P2WPKH", bip137P2WPKHAddress, bip137Message, bip137P2WPKH, true},
+		{"BIP-137 P2WPKH with P2PKH header", bip137P2WPKHAddress, bip137Message, bip137P2PKH, true},
+		{"BIP-137 wrong message", bip137P2PKHAddress, bip137Message + "!", bip137P2PKH, false},
+		{"BIP-137 wrong address", bip322P2WPKHAddress, bip137Message, bip137P2WPKH, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := s.VerifySignature(tt.address, tt.message, tt.signature)
+			if valid != tt.valid {
+				t.Errorf("got %v (%v), want %v", valid, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureRejectsMalformed(t *testing.T) {
+	s := NewWalletService(config.BitcoinConfig{}, nil)
+
+	tests := []struct {
+		name      string
+		address   string
+		signature string
+	}{
+		{"not base64", bip322P2WPKHAddress, "not a signature!"},
+		{"empty witness", bip322P2WPKHAddress, base64.StdEncoding.EncodeToString([]byte{0})},
+		{"truncated witness", bip322P2WPKHAddress, bip322P2WPKHHelloWorld[:40]},
+		{"invalid address", "bc1qinvalid", bip322P2WPKHHelloWorld},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := s.VerifySignature(tt.address, "Hello World", tt.signature)
+			if valid || err == nil {
+				t.Errorf("got %v (%v), want an error", valid, err)
+			}
+		})
+	}
+}
+
+// bip322Full returns the full form of a simple BIP-322 signature, the
+// serialized to_sign transaction carrying its witness
+func bip322Full(t *testing.T, s *WalletService, address, message, simple string) string {
+	t.Helper()
+
+	addr, err := s.decodeAddress(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(simple)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness, err := parseWitness(sigBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := bip322ToSign(bip322ToSpend(pkScript, message).TxHash(), witness).Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestBuildPurchasePSBTKeepsListingVersion(t *testing.T) {
+	seller, sellerScript := testP2WPKH(t)
+	buyer, _ := testP2WPKH(t)
+
+	// A version 1 listing with a lock time, signed with SIGHASH_SINGLE|ANYONECANPAY
+	inscription := chainhash.DoubleHashH([]byte("inscription"))
+	listingTx := wire.NewMsgTx(1)
+	listingTx.LockTime = 800000
+	listingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&inscription, 0), nil, nil))
+	listingTx.AddTxOut(wire.NewTxOut(10000, sellerScript))
+
+	utxo := wire.NewTxOut(546, sellerScript)
+	packet, err := psbt.NewFromUnsignedTx(listingTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet.Inputs[0].WitnessUtxo = utxo
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(utxo.PkScript, utxo.Value)
+	sig, err := txscript.RawTxInWitnessSignature(listingTx, txscript.NewTxSigHashes(listingTx, fetcher),
+		0, utxo.Value, utxo.PkScript, listingSighash, seller)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet.Inputs[0].SighashType = listingSighash
+	packet.Inputs[0].PartialSigs = []*psbt.PartialSig{{PubKey: seller.PubKey().SerializeCompressed(), Signature: sig}}
+	listing, err := packet.B64Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := chain.NewFakeBackend(&chaincfg.MainNetParams)
+	fake.SetFeeEstimates(chain.FeeEstimates{settlementConfTarget: 5})
+	buyerAddress := testWitnessAddress(t, buyer).EncodeAddress()
+	for i, value := range []int64{600, 50000} {
+		txid := chainhash.DoubleHashH([]byte{byte(i)})
+		fake.AddUTXO(buyerAddress, chain.UTXO{TxID: txid.String(), Value: value})
+	}
+	s := NewWalletService(config.BitcoinConfig{}, fake)
+
+	settlement, err := s.BuildPurchasePSBT(PurchasePSBTParams{
+		ListingPSBT:  listing,
+		Location:     inscription.String() + ":0:0",
+		BuyerAddress: buyerAddress,
+		Price:        10000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	built, err := decodePSBT(settlement.PSBT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built.UnsignedTx.Version != 1 || built.UnsignedTx.LockTime != 800000 {
+		t.Fatalf("got version %d and lock time %d, want the listing's 1 and 800000",
+			built.UnsignedTx.Version, built.UnsignedTx.LockTime)
+	}
+
+	// The seller's signature still holds on the settlement transaction
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range built.UnsignedTx.TxIn {
+		prevOuts.AddPrevOut(txIn.PreviousOutPoint, built.Inputs[i].WitnessUtxo)
+	}
+	signed := built.UnsignedTx.Copy()
+	signed.TxIn[1].Witness = wire.TxWitness{sig, seller.PubKey().SerializeCompressed()}
+	engine, err := txscript.NewEngine(utxo.PkScript, signed, 1, txscript.StandardVerifyFlags, nil,
+		txscript.NewTxSigHashes(signed, prevOuts), utxo.Value, prevOuts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Execute(); err != nil {
+		t.Fatalf("seller signature is invalid on the settlement: %v", err)
+	}
+
+	// Sellers signing different versions cannot share a transaction
+	sellers := []sellerInput{
+		{txIn: listingTx.TxIn[0], utxo: utxo, payment: listingTx.TxOut[0], version: 1},
+		{txIn: listingTx.TxIn[0], utxo: utxo, payment: listingTx.TxOut[0], version: 2},
+	}
+	if _, err := s.buildTransferPSBT(sellers, PurchasePSBTParams{BuyerAddress: buyerAddress, Price: 20000}); err == nil {
+		t.Fatal("expected listings with different versions to be rejected")
+	}
+}
+
+// testListingInput is an input of a test listing PSBT and how it is signed
+type testListingInput struct {
+	utxo    *wire.TxOut
+	signer  *btcec.PrivateKey // nil leaves the input unsigned
+	sighash txscript.SigHashType
+}
+
+// testListingOutpoint returns the outpoint spent by input i of a test listing
+func testListingOutpoint(i int) wire.OutPoint {
+	return wire.OutPoint{Hash: chainhash.DoubleHashH([]byte(fmt.Sprintf("listing-%d", i)))}
+}
+
+// testSatpoint returns the satpoint of an inscription in the UTXO spent by
+// input i of a test listing
+func testSatpoint(i int, offset int64) string {
+	return fmt.Sprintf("%s:%d", testListingOutpoint(i), offset)
+}
+
+// testListingPSBT builds and signs a listing PSBT spending testListingOutpoint(i)
+// with each input i, then lets edit change the packet before encoding it
+func testListingPSBT(t *testing.T, inputs []testListingInput, outputs []*wire.TxOut, edit func(packet *psbt.Packet)) string {
+	t.Helper()
+
+	tx := wire.NewMsgTx(2)
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for i, input := range inputs {
+		outPoint := testListingOutpoint(i)
+		tx.AddTxIn(wire.NewTxIn(&outPoint, nil, nil))
+		prevOuts.AddPrevOut(outPoint, input.utxo)
+	}
+	for _, output := range outputs {
+		tx.AddTxOut(output)
+	}
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashes := txscript.NewTxSigHashes(tx, prevOuts)
+	for i, input := range inputs {
+		packet.Inputs[i].WitnessUtxo = input.utxo
+		if input.signer == nil {
+			continue
+		}
+
+		sig, err := txscript.RawTxInWitnessSignature(tx, hashes, i, input.utxo.Value, input.utxo.PkScript,
+			input.sighash, input.signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packet.Inputs[i].SighashType = input.sighash
+		packet.Inputs[i].PartialSigs = []*psbt.PartialSig{{
+			PubKey:    input.signer.PubKey().SerializeCompressed(),
+			Signature: sig,
+		}}
+	}
+
+	if edit != nil {
+		edit(packet)
+	}
+
+	encoded, err := packet.B64Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return encoded
+}
+
+func TestValidatePSBT(t *testing.T) {
+	seller, sellerScript := testP2WPKH(t)
+	other, otherScript := testP2WPKH(t)
+	sellerAddress := testWitnessAddress(t, seller).EncodeAddress()
+
+	signed := func(utxo *wire.TxOut, signer *btcec.PrivateKey) testListingInput {
+		return testListingInput{utxo: utxo, signer: signer, sighash: listingSighash}
+	}
+	listed := func(value int64) testListingInput {
+		return signed(wire.NewTxOut(value, sellerScript), seller)
+	}
+	pays := func(values ...int64) []*wire.TxOut {
+		outputs := make([]*wire.TxOut, len(values))
+		for i, value := range values {
+			outputs[i] = wire.NewTxOut(value, sellerScript)
+		}
+		return outputs
+	}
+	lot := []ListedInscription{{InscriptionID: "lot1i0", Location: testSatpoint(1, 0)}}
+
+	tests := []struct {
+		name     string
+		inputs   []testListingInput
+		outputs  []*wire.TxOut
+		edit     func(packet *psbt.Packet)
+		encoded  string // replaces the built PSBT
+		location string // defaults to the first input's UTXO
+		seller   string // defaults to the seller's address
+		price    int64  // defaults to 10000
+		lot      []ListedInscription
+		code     string // empty when the listing is valid
+	}{
+		{
+			name:    "valid",
+			inputs:  []testListingInput{listed(546)},
+			outputs: pays(10000),
+		},
+		{
+			name:    "valid lot",
+			inputs:  []testListingInput{listed(546), listed(546)},
+			outputs: pays(6000, 4000),
+			lot:     lot,
+		},
+		{
+			name:    "not a PSBT",
+			encoded: "not a psbt",
+			code:    PSBTErrInvalidEncoding,
+		},
+		{
+			name:    "invalid seller address",
+			inputs:  []testListingInput{listed(546)},
+			outputs: pays(10000),
+			seller:  "bc1qnotanaddress",
+			code:    PSBTErrInvalidSellerAddr,
+		},
+		{
+			name:     "invalid location",
+			inputs:   []testListingInput{listed(546)},
+			outputs:  pays(10000),
+			location: "nowhere",
+			code:     PSBTErrInvalidLocation,
+		},
+		{
+			name:     "inscription not spent",
+			inputs:   []testListingInput{listed(546)},
+			outputs:  pays(10000),
+			location: testSatpoint(5, 0),
+			code:     PSBTErrInscriptionInput,
+		},
+		{
+			name:    "missing witness UTXO",
+			inputs:  []testListingInput{listed(546)},
+			outputs: pays(10000),
+			edit:    func(packet *psbt.Packet) { packet.Inputs[0].WitnessUtxo = nil },
+			code:    PSBTErrMissingUTXO,
+		},
+		{
+			name:    "input not owned by the seller",
+			inputs:  []testListingInput{signed(wire.NewTxOut(546, otherScript), other)},
+			outputs: pays(10000),
+			code:    PSBTErrInputNotOwned,
+		},
+		{
+			name:     "offset outside the UTXO",
+			inputs:   []testListingInput{listed(546)},
+			outputs:  pays(10000),
+			location: testSatpoint(0, 546),
+			code:     PSBTErrInvalidOffset,
+		},
+		{
+			name:    "unsigned input",
+			inputs:  []testListingInput{{utxo: wire.NewTxOut(546, sellerScript)}},
+			outputs: pays(10000),
+			code:    PSBTErrNotSigned,
+		},
+		{
+			name: "SIGHASH_ALL",
+			inputs: []testListingInput{{
+				utxo:    wire.NewTxOut(546, sellerScript),
+				signer:  seller,
+				sighash: txscript.SigHashAll,
+			}},
+			outputs: pays(10000),
+			code:    PSBTErrInvalidSighash,
+		},
+		{
+			name:    "signed by another key",
+			inputs:  []testListingInput{signed(wire.NewTxOut(546, sellerScript), other)},
+			outputs: pays(10000),
+			code:    PSBTErrInvalidSignature,
+		},
+		{
+			name:    "signature over another payment",
+			inputs:  []testListingInput{listed(546)},
+			outputs: pays(10000),
+			edit:    func(packet *psbt.Packet) { packet.UnsignedTx.TxOut[0].Value = 20000 },
+			price:   20000,
+			code:    PSBTErrInvalidSignature,
+		},
+		{
+			name:    "no paired output",
+			inputs:  []testListingInput{listed(546), listed(546)},
+			outputs: pays(10000),
+			lot:     lot,
+			code:    PSBTErrPaymentMissing,
+		},
+		{
+			name:    "paired output pays someone else",
+			inputs:  []testListingInput{listed(546)},
+			outputs: []*wire.TxOut{wire.NewTxOut(10000, otherScript)},
+			code:    PSBTErrInvalidPayment,
+		},
+		{
+			name:    "paired output pays another price",
+			inputs:  []testListingInput{listed(546)},
+			outputs: pays(9000),
+			code:    PSBTErrInvalidPayment,
+		},
+		{
+			name:    "lot pays another price",
+			inputs:  []testListingInput{listed(546), listed(546)},
+			outputs: pays(6000, 3000),
+			lot:     lot,
+			code:    PSBTErrInvalidPayment,
+		},
+		{
+			name:    "lot inscriptions in one UTXO",
+			inputs:  []testListingInput{listed(1000)},
+			outputs: pays(10000),
+			lot:     []ListedInscription{{InscriptionID: "lot1i0", Location: testSatpoint(0, 500)}},
+			code:    PSBTErrInscriptionInput,
+		},
+	}
+
+	s := NewWalletService(config.BitcoinConfig{}, chain.NewFakeBackend(&chaincfg.MainNetParams))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := tt.encoded
+			if encoded == "" {
+				encoded = testListingPSBT(t, tt.inputs, tt.outputs, tt.edit)
+			}
+
+			params := ListingPSBTParams{
+				InscriptionID: "listedi0",
+				Location:      tt.location,
+				SellerAddress: tt.seller,
+				Price:         tt.price,
+				Lot:           tt.lot,
+			}
+			if params.Location == "" {
+				params.Location = testSatpoint(0, 0)
+			}
+			if params.SellerAddress == "" {
+				params.SellerAddress = sellerAddress
+			}
+			if params.Price == 0 {
+				params.Price = 10000
+			}
+
+			err := s.ValidatePSBT(encoded, params)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("got %v, want a valid listing", err)
+				}
+				return
+			}
+
+			var validationErr *PSBTValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got %v, want a %s validation error", err, tt.code)
+			}
+			if validationErr.Code != tt.code {
+				t.Errorf("got code %s (%s), want %s", validationErr.Code, validationErr.Message, tt.code)
+			}
+		})
+	}
+}
+
+// testP2WPKH returns a new key and its P2WPKH script
+func testP2WPKH(t *testing.T) (*btcec.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := txscript.PayToAddrScript(testWitnessAddress(t, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, script
+}
+
+// testWitnessAddress returns the mainnet P2WPKH address of a key
+func testWitnessAddress(t *testing.T, key *btcec.PrivateKey) btcutil.Address {
+	t.Helper()
+
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(key.PubKey().SerializeCompressed()), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return addr
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// auctionState holds auction fields recorded by an event, keyed by their JSON
+// names so that replaying the event can decode them onto the auction
+type auctionState map[string]interface{}
+
+// appendAuctionEvent records an event in an auction's log as part of tx. The
+// payload is an auctionState or the whole auction, and hidden holds the state
+// that must not be sent to clients. The auction row
+// must be locked or written in tx first so that events are numbered in order.
+func appendAuctionEvent(tx *sqlx.Tx, auctionID string, eventType models.AuctionEventType, payload interface{}, hidden auctionState) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var hiddenJSON interface{}
+	if hidden != nil {
+		if hiddenJSON, err = json.Marshal(hidden); err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO auction_events (id, auction_id, sequence, type, payload, hidden, created_at)
+			 SELECT $1, $2, COALESCE(MAX(sequence), 0) + 1, $3, $4, $5, $6
+			 FROM auction_events WHERE auction_id = $2`
+	_, err = tx.Exec(query, uuid.New().String(), auctionID, eventType, payloadJSON, hiddenJSON, time.Now())
+	return err
+}
+
+// savedState returns the fields of an auction that Update saves, including the
+// empty ones the JSON of the auction leaves out
+func savedState(auction *models.Auction) auctionState {
+	return auctionState{
+		"type":                 auction.Type,
+		"nft_id":               auction.NFTID,
+		"seller_wallet_id":     auction.SellerWalletID,
+		"start_price":          auction.StartPrice,
+		"reserve_price":        auction.ReservePrice,
+		"buy_now_price":        auction.BuyNowPrice,
+		"current_bid":          auction.CurrentBid,
+		"current_bidder_id":    auction.CurrentBidderID,
+		"start_time":           auction.StartTime,
+		"end_time":             auction.EndTime,
+		"status":               auction.Status,
+		"settlement_txid":      auction.SettlementTxID,
+		"settlement_wallet_id": auction.SettlementWalletID,
+		"extension_window":     auction.ExtensionWindow,
+		"extension_length":     auction.ExtensionLength,
+		"max_extensions":       auction.MaxExtensions,
+		"extension_count":      auction.ExtensionCount,
+		"bid_increment":        auction.BidIncrement,
+		"floor_price":          auction.FloorPrice,
+		"price_schedule":       auction.PriceSchedule,
+		"price_step_interval":  auction.PriceStepInterval,
+		"reveal_end_time":      auction.RevealEndTime,
+		"sealed_pricing":       auction.SealedPricing,
+	}
+}
+
+// GetEvents retrieves the events of an auction after a sequence number, in order
+func (r *AuctionRepository) GetEvents(auctionID string, after int) ([]models.AuctionEvent, error) {
+	events := []models.AuctionEvent{}
+	query := `SELECT id, auction_id, sequence, type, payload, hidden, created_at
+			 FROM auction_events
+			 WHERE auction_id = $1 AND sequence > $2
+			 ORDER BY sequence ASC`
+
+	err := r.db.GetDB().Select(&events, query, auctionID, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// RebuildAuction locks an auction row, runs project on its whole event log and
+// overwrites the row with the auction project returns
+func (r *AuctionRepository) RebuildAuction(id string, project func(events []models.AuctionEvent) (*models.Auction, error)) (*models.Auction, error) {
+	var auction *models.Auction
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		if _, err := lockAuction(tx, id); err != nil {
+			return err
+		}
+
+		events := []models.AuctionEvent{}
+		query := `SELECT id, auction_id, sequence, type, payload, hidden, created_at
+				 FROM auction_events WHERE auction_id = $1 ORDER BY sequence ASC`
+		if err := tx.Select(&events, query, id); err != nil {
+			return err
+		}
+
+		var err error
+		auction, err = project(events)
+		if err != nil {
+			return err
+		}
+
+		query = `UPDATE auctions SET type = $1, nft_id = $2, seller_wallet_id = $3, start_price = $4,
+				reserve_price = $5, buy_now_price = $6, current_bid = $7, current_bidder_id = $8,
+				start_time = $9, end_time = $10, status = $11, psbt = $12, settlement_psbt = $13,
+				settlement_txid = $14, settlement_wallet_id = $15, extension_window = $16,
+				extension_length = $17, max_extensions = $18, extension_count = $19, bid_increment = $20,
+				floor_price = $21, price_schedule = $22, price_step_interval = $23, reveal_end_time = $24,
+				sealed_pricing = $25, cancellation_penalty = $26, proxy_max_bid = $27,
+				proxy_wallet_id = $28, created_at = $29, updated_at = $30 WHERE id = $31`
+		_, err = tx.Exec(query,
+			auction.Type, auction.NFTID, auction.SellerWalletID, auction.StartPrice,
+			auction.ReservePrice, auction.BuyNowPrice, auction.CurrentBid, auction.CurrentBidderID,
+			auction.StartTime, auction.EndTime, auction.Status, auction.PSBT, auction.SettlementPSBT,
+			auction.SettlementTxID, auction.SettlementWalletID, auction.ExtensionWindow,
+			auction.ExtensionLength, auction.MaxExtensions, auction.ExtensionCount, auction.BidIncrement,
+			auction.FloorPrice, auction.PriceSchedule, auction.PriceStepInterval, auction.RevealEndTime,
+			auction.SealedPricing, auction.CancelPenalty, auction.ProxyMaxBid,
+			auction.ProxyWalletID, auction.CreatedAt, auction.UpdatedAt, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return auction, nil
+}
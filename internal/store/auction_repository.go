@@ -2,6 +2,8 @@ package store
 
 import (
 	"database/sql"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,8 +26,11 @@ func NewAuctionRepository(db *Database) *AuctionRepository {
 // GetByID retrieves an auction by ID
 func (r *AuctionRepository) GetByID(id string) (*models.Auction, error) {
 	auction := &models.Auction{}
-	query := `SELECT id, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
+	query := `SELECT id, type, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
 			  current_bid, current_bidder_id, start_time, end_time, status, psbt, 
+			  settlement_psbt, settlement_txid, settlement_wallet_id, extension_window, extension_length,
+			  max_extensions, extension_count, bid_increment, floor_price, price_schedule,
+			  price_step_interval, reveal_end_time, sealed_pricing, cancellation_penalty, 
 			  created_at, updated_at
 			  FROM auctions WHERE id = $1`
 
@@ -49,7 +54,8 @@ func (r *AuctionRepository) GetByIDWithNFT(id string) (*models.Auction, error) {
 
 	// Fetch associated NFT
 	query := `SELECT id, wallet_id, token_id, inscription_id, collection, title, 
-			  description, image_url, content_url, metadata, created_at, updated_at, auction_id
+			  description, image_url, content_url, metadata, created_at, updated_at, auction_id, location,
+			  content_type, genesis_height, sat_number
 			  FROM nfts WHERE id = $1`
 
 	nft := &models.NFT{}
@@ -60,6 +66,16 @@ func (r *AuctionRepository) GetByIDWithNFT(id string) (*models.Auction, error) {
 
 	auction.NFT = nft
 
+	// Fetch the NFTs of a lot
+	lot, err := r.GetLot(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lot) > 0 {
+		auction.Lot = lot
+	}
+
 	// Fetch bids
 	bids, err := r.GetBidsByAuctionID(id)
 	if err != nil {
@@ -96,11 +112,23 @@ func (r *AuctionRepository) List(params models.AuctionParams) ([]models.Auction,
 		} else {
 			whereClause += ` AND`
 		}
-		whereClause += ` a.status = $` + string('0'+argCount)
+		whereClause += ` a.status = $` + strconv.Itoa(argCount)
 		args = append(args, params.Status)
 		argCount++
 	}
 
+	// Add type filter if provided
+	if params.Type != "" {
+		if whereClause == "" {
+			whereClause = ` WHERE`
+		} else {
+			whereClause += ` AND`
+		}
+		whereClause += ` a.type = $` + strconv.Itoa(argCount)
+		args = append(args, params.Type)
+		argCount++
+	}
+
 	// Add seller filter if provided
 	if params.SellerID != "" {
 		if whereClause == "" {
@@ -110,7 +138,7 @@ func (r *AuctionRepository) List(params models.AuctionParams) ([]models.Auction,
 		}
 		// Join with wallets to filter by seller user ID
 		baseQuery += ` JOIN wallets w ON a.seller_wallet_id = w.id`
-		whereClause += ` w.user_id = $` + string('0'+argCount)
+		whereClause += ` w.user_id = $` + strconv.Itoa(argCount)
 		args = append(args, params.SellerID)
 		argCount++
 	}
@@ -125,7 +153,7 @@ func (r *AuctionRepository) List(params models.AuctionParams) ([]models.Auction,
 		// Subquery to find auctions where user has placed bids
 		whereClause += ` a.id IN (SELECT auction_id FROM bids b 
 								 JOIN wallets w ON b.wallet_id = w.id 
-								 WHERE w.user_id = $` + string('0'+argCount) + `)`
+								 WHERE w.user_id = $` + strconv.Itoa(argCount) + `)`
 		args = append(args, params.BidderID)
 		argCount++
 	}
@@ -143,11 +171,14 @@ func (r *AuctionRepository) List(params models.AuctionParams) ([]models.Auction,
 
 	// Get paginated results
 	offset := (params.Page - 1) * params.PageSize
-	selectQuery := `SELECT a.id, a.nft_id, a.seller_wallet_id, a.start_price, a.reserve_price, 
+	selectQuery := `SELECT a.id, a.type, a.nft_id, a.seller_wallet_id, a.start_price, a.reserve_price, 
 				   a.buy_now_price, a.current_bid, a.current_bidder_id, a.start_time, a.end_time, 
-				   a.status, a.psbt, a.created_at, a.updated_at ` +
-		baseQuery + ` ORDER BY a.end_time ASC LIMIT $` + string('0'+argCount) +
-		` OFFSET $` + string('0'+argCount+1)
+				   a.status, a.psbt, a.settlement_psbt, a.settlement_txid, a.settlement_wallet_id, 
+				   a.extension_window, a.extension_length, a.max_extensions, a.extension_count, a.bid_increment, 
+				   a.floor_price, a.price_schedule, a.price_step_interval, a.reveal_end_time, a.sealed_pricing, 
+				   a.cancellation_penalty, a.created_at, a.updated_at ` +
+		baseQuery + ` ORDER BY a.end_time ASC LIMIT $` + strconv.Itoa(argCount) +
+		` OFFSET $` + strconv.Itoa(argCount+1)
 	args = append(args, params.PageSize, offset)
 
 	err = r.db.GetDB().Select(&auctions, selectQuery, args...)
@@ -159,7 +190,8 @@ func (r *AuctionRepository) List(params models.AuctionParams) ([]models.Auction,
 	for i := range auctions {
 		// Fetch associated NFT
 		query := `SELECT id, wallet_id, token_id, inscription_id, collection, title, 
-				 description, image_url, content_url, metadata, created_at, updated_at, auction_id
+				 description, image_url, content_url, metadata, created_at, updated_at, auction_id, location,
+				 content_type, genesis_height, sat_number
 				 FROM nfts WHERE id = $1`
 
 		nft := &models.NFT{}
@@ -170,6 +202,16 @@ func (r *AuctionRepository) List(params models.AuctionParams) ([]models.Auction,
 
 		auctions[i].NFT = nft
 
+		// Fetch the NFTs of a lot
+		lot, err := r.GetLot(auctions[i].ID)
+		if err != nil {
+			continue
+		}
+
+		if len(lot) > 0 {
+			auctions[i].Lot = lot
+		}
+
 		// Fetch top 3 bids
 		bids, err := r.GetTopBidsByAuctionID(auctions[i].ID, 3)
 		if err != nil {
@@ -203,53 +245,344 @@ func (r *AuctionRepository) Create(auction *models.Auction) error {
 		}
 
 		// Insert auction
-		query := `INSERT INTO auctions (id, nft_id, seller_wallet_id, start_price, reserve_price, 
-				 buy_now_price, start_time, end_time, status, psbt, created_at, updated_at) 
-				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+		query := `INSERT INTO auctions (id, type, nft_id, seller_wallet_id, start_price, reserve_price, 
+				 buy_now_price, start_time, end_time, status, psbt, extension_window, extension_length, 
+				 max_extensions, bid_increment, floor_price, price_schedule, price_step_interval, 
+				 reveal_end_time, sealed_pricing, created_at, updated_at) 
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, 
+				 $19, $20, $21, $22)`
 
 		_, err := tx.Exec(query,
-			auction.ID, auction.NFTID, auction.SellerWalletID, auction.StartPrice,
+			auction.ID, auction.Type, auction.NFTID, auction.SellerWalletID, auction.StartPrice,
 			auction.ReservePrice, auction.BuyNowPrice, auction.StartTime,
-			auction.EndTime, auction.Status, auction.PSBT, auction.CreatedAt, auction.UpdatedAt)
+			auction.EndTime, auction.Status, auction.PSBT, auction.ExtensionWindow,
+			auction.ExtensionLength, auction.MaxExtensions, auction.BidIncrement, auction.FloorPrice,
+			auction.PriceSchedule, auction.PriceStepInterval, auction.RevealEndTime, auction.SealedPricing,
+			auction.CreatedAt, auction.UpdatedAt)
 
 		if err != nil {
 			return err
 		}
 
-		// Update NFT with auction ID
-		query = `UPDATE nfts SET auction_id = $1, updated_at = $2 WHERE id = $3`
-		_, err = tx.Exec(query, auction.ID, now, auction.NFTID)
-		if err != nil {
-			return err
+		// Lock every NFT of the auction, which must not be on another auction
+		nftIDs := []string{auction.NFTID}
+		if len(auction.Lot) > 0 {
+			nftIDs = nftIDs[:0]
+			for _, nft := range auction.Lot {
+				nftIDs = append(nftIDs, nft.ID)
+			}
 		}
 
-		return nil
+		for position, nftID := range nftIDs {
+			query = `UPDATE nfts SET auction_id = $1, updated_at = $2 WHERE id = $3 AND auction_id IS NULL`
+			result, err := tx.Exec(query, auction.ID, now, nftID)
+			if err != nil {
+				return err
+			}
+
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			if rows == 0 {
+				return fmt.Errorf("NFT %s is already on auction", nftID)
+			}
+
+			if len(auction.Lot) > 0 {
+				query = `INSERT INTO auction_lots (auction_id, nft_id, position) VALUES ($1, $2, $3)`
+				if _, err := tx.Exec(query, auction.ID, nftID, position); err != nil {
+					return err
+				}
+			}
+		}
+
+		return appendAuctionEvent(tx, auction.ID, models.AuctionEventCreated, auction,
+			auctionState{"psbt": auction.PSBT})
 	})
 }
 
+// GetLot retrieves the NFTs of a lot auction in order. Auctions of a single
+// NFT have none.
+func (r *AuctionRepository) GetLot(auctionID string) ([]models.NFT, error) {
+	nfts := []models.NFT{}
+	query := `SELECT n.id, n.wallet_id, n.token_id, n.inscription_id, n.collection, n.title, 
+			 n.description, n.image_url, n.content_url, n.metadata, n.created_at, n.updated_at, 
+			 n.auction_id, n.location, n.content_type, n.genesis_height, n.sat_number 
+			 FROM auction_lots l JOIN nfts n ON n.id = l.nft_id 
+			 WHERE l.auction_id = $1 
+			 ORDER BY l.position ASC`
+
+	err := r.db.GetDB().Select(&nfts, query, auctionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return nfts, nil
+}
+
 // Update updates an auction
 func (r *AuctionRepository) Update(auction *models.Auction) error {
 	auction.UpdatedAt = time.Now()
 
 	query := `UPDATE auctions SET nft_id = $1, seller_wallet_id = $2, start_price = $3, 
 			 reserve_price = $4, buy_now_price = $5, current_bid = $6, current_bidder_id = $7,
-			 start_time = $8, end_time = $9, status = $10, psbt = $11, updated_at = $12
-			 WHERE id = $13`
+			 start_time = $8, end_time = $9, status = $10, psbt = $11, settlement_psbt = $12,
+			 settlement_txid = $13, settlement_wallet_id = $14, extension_window = $15,
+			 extension_length = $16, max_extensions = $17, extension_count = $18, bid_increment = $19,
+			 type = $20, floor_price = $21, price_schedule = $22, price_step_interval = $23,
+			 reveal_end_time = $24, sealed_pricing = $25, updated_at = $26 WHERE id = $27`
 
-	_, err := r.db.GetDB().Exec(query,
-		auction.NFTID, auction.SellerWalletID, auction.StartPrice,
-		auction.ReservePrice, auction.BuyNowPrice, auction.CurrentBid,
-		auction.CurrentBidderID, auction.StartTime, auction.EndTime,
-		auction.Status, auction.PSBT, auction.UpdatedAt, auction.ID)
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query,
+			auction.NFTID, auction.SellerWalletID, auction.StartPrice,
+			auction.ReservePrice, auction.BuyNowPrice, auction.CurrentBid,
+			auction.CurrentBidderID, auction.StartTime, auction.EndTime,
+			auction.Status, auction.PSBT, auction.SettlementPSBT,
+			auction.SettlementTxID, auction.SettlementWalletID, auction.ExtensionWindow,
+			auction.ExtensionLength, auction.MaxExtensions, auction.ExtensionCount,
+			auction.BidIncrement, auction.Type, auction.FloorPrice, auction.PriceSchedule,
+			auction.PriceStepInterval, auction.RevealEndTime, auction.SealedPricing, auction.UpdatedAt,
+			auction.ID)
+		if err != nil {
+			return err
+		}
 
-	return err
+		return appendAuctionEvent(tx, auction.ID, models.AuctionEventUpdated, savedState(auction),
+			auctionState{"psbt": auction.PSBT, "settlement_psbt": auction.SettlementPSBT})
+	})
 }
 
 // UpdateStatus updates the status of an auction
 func (r *AuctionRepository) UpdateStatus(id string, status models.AuctionStatus) error {
-	query := `UPDATE auctions SET status = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.GetDB().Exec(query, status, time.Now(), id)
-	return err
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		query := `UPDATE auctions SET status = $1, updated_at = $2 WHERE id = $3`
+		if _, err := tx.Exec(query, status, time.Now(), id); err != nil {
+			return err
+		}
+
+		return appendAuctionEvent(tx, id, models.StatusEventType(status), auctionState{"status": status}, nil)
+	})
+}
+
+// TransitionStatus moves an auction from one status to another and reports
+// whether it was still in the expected status
+func (r *AuctionRepository) TransitionStatus(id string, from, to models.AuctionStatus) (bool, error) {
+	var moved bool
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		query := `UPDATE auctions SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4`
+		result, err := tx.Exec(query, to, time.Now(), id, from)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil || rows == 0 {
+			return err
+		}
+		moved = true
+
+		return appendAuctionEvent(tx, id, models.StatusEventType(to), auctionState{"status": to}, nil)
+	})
+
+	return moved, err
+}
+
+// EndAuction closes an active auction whose end time has passed. Auctions
+// without a bid meeting the reserve price are cancelled and release their NFT,
+// the others end. It returns the new status, or an empty status if the auction
+// was no longer active or had been extended.
+func (r *AuctionRepository) EndAuction(id string, now time.Time) (models.AuctionStatus, error) {
+	var status models.AuctionStatus
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		// Decide the outcome on the current row
+		query := `UPDATE auctions SET status = CASE 
+					WHEN current_bid IS NULL OR current_bid < COALESCE(reserve_price, 0) THEN $1 
+					ELSE $2 END, 
+				 updated_at = $3 
+				 WHERE id = $4 AND status = $5 AND end_time <= $6 
+				 RETURNING status`
+		err := tx.Get(&status, query, models.AuctionStatusCancelled, models.AuctionStatusEnded,
+			now, id, models.AuctionStatusActive, now)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				status = ""
+				return nil
+			}
+			return err
+		}
+
+		err = appendAuctionEvent(tx, id, models.StatusEventType(status), auctionState{"status": status}, nil)
+		if err != nil {
+			return err
+		}
+
+		if status == models.AuctionStatusCancelled {
+			// Remove the auction_id from NFT
+			query = `UPDATE nfts SET auction_id = NULL, updated_at = $1 
+					WHERE auction_id = $2`
+			_, err = tx.Exec(query, now, id)
+			return err
+		}
+
+		return nil
+	})
+
+	return status, err
+}
+
+// CancelAuction cancels an auction that is still in one of the given statuses
+// and releases its NFT. It reports whether the auction was cancelled.
+func (r *AuctionRepository) CancelAuction(id string, from ...models.AuctionStatus) (bool, error) {
+	var cancelled bool
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		now := time.Now()
+
+		// Only cancel the auction from an expected status
+		query, args, err := sqlx.In(`UPDATE auctions SET status = ?, updated_at = ? 
+				 WHERE id = ? AND status IN (?)`, models.AuctionStatusCancelled, now, id, from)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(tx.Rebind(query), args...)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil || rows == 0 {
+			return err
+		}
+		cancelled = true
+
+		err = appendAuctionEvent(tx, id, models.AuctionEventCancelled,
+			auctionState{"status": models.AuctionStatusCancelled}, nil)
+		if err != nil {
+			return err
+		}
+
+		// Remove the auction_id from NFT
+		query = `UPDATE nfts SET auction_id = NULL, updated_at = $1 
+				WHERE auction_id = $2`
+		_, err = tx.Exec(query, now, id)
+		return err
+	})
+
+	return cancelled, err
+}
+
+// Reprice replaces the price and listing PSBT of an active auction. It reports
+// whether the auction was still active.
+func (r *AuctionRepository) Reprice(id string, price int64, psbt string) (bool, error) {
+	var repriced bool
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		query := `UPDATE auctions SET start_price = $1, psbt = $2, updated_at = $3 
+				 WHERE id = $4 AND status = $5`
+		result, err := tx.Exec(query, price, psbt, time.Now(), id, models.AuctionStatusActive)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil || rows == 0 {
+			return err
+		}
+		repriced = true
+
+		return appendAuctionEvent(tx, id, models.AuctionEventRepriced,
+			auctionState{"start_price": price}, auctionState{"psbt": psbt})
+	})
+
+	return repriced, err
+}
+
+// UpdateDraft saves the prices, times and listing PSBT of a draft auction. It
+// reports whether the auction was still a draft.
+func (r *AuctionRepository) UpdateDraft(auction *models.Auction) (bool, error) {
+	auction.UpdatedAt = time.Now()
+
+	var updated bool
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		query := `UPDATE auctions SET start_price = $1, reserve_price = $2, buy_now_price = $3, 
+				 floor_price = $4, start_time = $5, end_time = $6, reveal_end_time = $7, psbt = $8, 
+				 updated_at = $9 WHERE id = $10 AND status = $11`
+		result, err := tx.Exec(query,
+			auction.StartPrice, auction.ReservePrice, auction.BuyNowPrice, auction.FloorPrice,
+			auction.StartTime, auction.EndTime, auction.RevealEndTime, auction.PSBT, auction.UpdatedAt,
+			auction.ID, models.AuctionStatusDraft)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil || rows == 0 {
+			return err
+		}
+		updated = true
+
+		return appendAuctionEvent(tx, auction.ID, models.AuctionEventUpdated, auctionState{
+			"start_price":     auction.StartPrice,
+			"reserve_price":   auction.ReservePrice,
+			"buy_now_price":   auction.BuyNowPrice,
+			"floor_price":     auction.FloorPrice,
+			"start_time":      auction.StartTime,
+			"end_time":        auction.EndTime,
+			"reveal_end_time": auction.RevealEndTime,
+		}, auctionState{"psbt": auction.PSBT})
+	})
+
+	return updated, err
+}
+
+// CancelBySeller locks the auction row, runs decide against its current state
+// and the number of bids and sealed commitments it received, then cancels it
+// with the penalty decide returns and releases its NFT, all in one transaction.
+func (r *AuctionRepository) CancelBySeller(id string, decide func(auction *models.Auction, bids int) (*int64, error)) error {
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		auction, err := lockAuction(tx, id)
+		if err != nil {
+			return err
+		}
+
+		// Count open and sealed bids alike
+		var bids int
+		query := `SELECT (SELECT COUNT(*) FROM bids WHERE auction_id = $1) + 
+				 (SELECT COUNT(*) FROM bid_commitments WHERE auction_id = $1)`
+		if err := tx.Get(&bids, query, id); err != nil {
+			return err
+		}
+
+		penalty, err := decide(auction, bids)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		query = `UPDATE auctions SET status = $1, cancellation_penalty = $2, updated_at = $3 WHERE id = $4`
+		if _, err := tx.Exec(query, models.AuctionStatusCancelled, penalty, now, id); err != nil {
+			return err
+		}
+
+		err = appendAuctionEvent(tx, id, models.AuctionEventCancelled, auctionState{
+			"status":               models.AuctionStatusCancelled,
+			"cancellation_penalty": penalty,
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		// Remove the auction_id from NFT
+		query = `UPDATE nfts SET auction_id = NULL, updated_at = $1 
+				WHERE auction_id = $2`
+		_, err = tx.Exec(query, now, id)
+		return err
+	})
 }
 
 // CompleteAuction completes an auction and releases the NFT
@@ -265,6 +598,11 @@ func (r *AuctionRepository) CompleteAuction(auctionID string, status models.Auct
 			return err
 		}
 
+		err = appendAuctionEvent(tx, auctionID, models.StatusEventType(status), auctionState{"status": status}, nil)
+		if err != nil {
+			return err
+		}
+
 		if status == models.AuctionStatusCompleted {
 			// If completed, keep the auction_id (for history)
 			return nil
@@ -278,42 +616,83 @@ func (r *AuctionRepository) CompleteAuction(auctionID string, status models.Auct
 	})
 }
 
-// CreateBid creates a new bid
-func (r *AuctionRepository) CreateBid(bid *models.Bid) error {
-	// Use transaction to update auction if bid is higher than current
+// SetSettlementPSBT stores the settlement PSBT prepared for the winner of an
+// auction and the wallet receiving the NFT
+func (r *AuctionRepository) SetSettlementPSBT(auctionID, psbt, walletID string) error {
 	return r.db.Transaction(func(tx *sqlx.Tx) error {
-		if bid.ID == "" {
-			bid.ID = uuid.New().String()
+		query := `UPDATE auctions SET settlement_psbt = $1, settlement_wallet_id = $2, updated_at = $3 
+				 WHERE id = $4`
+		if _, err := tx.Exec(query, psbt, walletID, time.Now(), auctionID); err != nil {
+			return err
 		}
-		now := time.Now()
-		bid.CreatedAt = now
 
-		// Insert bid
-		query := `INSERT INTO bids (id, auction_id, bidder_id, wallet_id, amount, created_at, accepted) 
-				 VALUES ($1, $2, $3, $4, $5, $6, $7)`
+		return appendAuctionEvent(tx, auctionID, models.AuctionEventSettlementPrepared,
+			auctionState{"settlement_wallet_id": walletID}, auctionState{"settlement_psbt": psbt})
+	})
+}
 
-		_, err := tx.Exec(query,
-			bid.ID, bid.AuctionID, bid.BidderID, bid.WalletID,
-			bid.Amount, bid.CreatedAt, bid.Accepted)
+// StartSettlement records the broadcast settlement transaction of an auction
+func (r *AuctionRepository) StartSettlement(auctionID, txid string) error {
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		query := `UPDATE auctions SET status = $1, settlement_txid = $2, updated_at = $3 
+				 WHERE id = $4 AND status IN ($5, $6, $7)`
+		result, err := tx.Exec(query, models.AuctionStatusSettling, txid, time.Now(),
+			auctionID, models.AuctionStatusActive, models.AuctionStatusEnded, models.AuctionStatusSettlementFailed)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return fmt.Errorf("auction is not awaiting settlement")
+		}
+
+		return appendAuctionEvent(tx, auctionID, models.AuctionEventSettlementStarted, auctionState{
+			"status":          models.AuctionStatusSettling,
+			"settlement_txid": txid,
+		}, nil)
+	})
+}
 
+// CompleteSettlement marks an auction as settled and moves its NFTs, keyed by
+// ID, to the buyer's wallet at their new locations
+func (r *AuctionRepository) CompleteSettlement(auctionID, walletID string, locations map[string]string) error {
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		now := time.Now()
+
+		// Update auction status
+		query := `UPDATE auctions SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4`
+		result, err := tx.Exec(query, models.AuctionStatusSettled, now, auctionID, models.AuctionStatusSettling)
 		if err != nil {
 			return err
 		}
 
-		// Check if this is the highest bid
-		var currentBid sql.NullInt64
-		query = `SELECT current_bid FROM auctions WHERE id = $1`
-		err = tx.Get(&currentBid, query, bid.AuctionID)
+		rows, err := result.RowsAffected()
 		if err != nil {
 			return err
 		}
 
-		if !currentBid.Valid || bid.Amount > currentBid.Int64 {
-			// Update auction with new highest bid
-			query = `UPDATE auctions SET current_bid = $1, current_bidder_id = $2, updated_at = $3 
+		if rows == 0 {
+			return fmt.Errorf("auction is not settling")
+		}
+
+		err = appendAuctionEvent(tx, auctionID, models.AuctionEventSettled, auctionState{
+			"status":    models.AuctionStatusSettled,
+			"locations": locations,
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		// Transfer the NFTs and release them from the auction
+		for nftID, location := range locations {
+			query = `UPDATE nfts SET wallet_id = $1, location = $2, auction_id = NULL, updated_at = $3 
 					WHERE id = $4`
-			_, err = tx.Exec(query, bid.Amount, bid.BidderID, now, bid.AuctionID)
-			if err != nil {
+			if _, err := tx.Exec(query, walletID, location, now, nftID); err != nil {
 				return err
 			}
 		}
@@ -322,10 +701,167 @@ func (r *AuctionRepository) CreateBid(bid *models.Bid) error {
 	})
 }
 
+// GetSettlingAuctions retrieves auctions whose settlement transaction is unconfirmed
+func (r *AuctionRepository) GetSettlingAuctions() ([]models.Auction, error) {
+	auctions := []models.Auction{}
+	query := `SELECT id, type, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
+			 current_bid, current_bidder_id, start_time, end_time, status, psbt, 
+			 settlement_psbt, settlement_txid, settlement_wallet_id, extension_window, extension_length,
+			 max_extensions, extension_count, bid_increment, floor_price, price_schedule,
+			 price_step_interval, reveal_end_time, sealed_pricing, cancellation_penalty, 
+			 created_at, updated_at
+			 FROM auctions 
+			 WHERE status = $1
+			 ORDER BY updated_at ASC`
+
+	err := r.db.GetDB().Select(&auctions, query, models.AuctionStatusSettling)
+	if err != nil {
+		return nil, err
+	}
+
+	return auctions, nil
+}
+
+// lockAuction locks an auction row for the rest of the transaction, including
+// the proxy maximum of the current bidder
+func lockAuction(tx *sqlx.Tx, id string) (*models.Auction, error) {
+	auction := &models.Auction{}
+	query := `SELECT id, type, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
+			 current_bid, current_bidder_id, start_time, end_time, status, psbt, 
+			 settlement_psbt, settlement_txid, settlement_wallet_id, extension_window, extension_length,
+			 max_extensions, extension_count, bid_increment, floor_price, price_schedule,
+			 price_step_interval, reveal_end_time, sealed_pricing, proxy_max_bid, proxy_wallet_id,
+			 created_at, updated_at
+			 FROM auctions WHERE id = $1 FOR UPDATE`
+
+	err := tx.Get(auction, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("auction not found")
+		}
+		return nil, err
+	}
+
+	return auction, nil
+}
+
+// PlaceBid locks the auction row, runs resolve against its current state and
+// records the bids it returns, in order, along with the new high bid, all in
+// one transaction. Concurrent bids on the same auction are serialized by the
+// row lock. resolve updates the auction's high bid, proxy maximum, end time
+// and status, which are saved with the bids. Only the bid that becomes the
+// high bid is accepted; bids a proxy outbid right away are recorded but not.
+func (r *AuctionRepository) PlaceBid(auctionID string, resolve func(auction *models.Auction) ([]*models.Bid, error)) error {
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		auction, err := lockAuction(tx, auctionID)
+		if err != nil {
+			return err
+		}
+
+		// Resolve the bid against the locked auction
+		endTime, status := auction.EndTime, auction.Status
+		bids, err := resolve(auction)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, bid := range bids {
+			if bid.ID == "" {
+				bid.ID = uuid.New().String()
+			}
+			bid.CreatedAt = now
+			bid.Accepted = isHighBid(auction, bid)
+
+			// Insert bid
+			query := `INSERT INTO bids (id, auction_id, bidder_id, wallet_id, amount, created_at, accepted, 
+					 max_amount, automatic, signature, message) 
+					 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+			_, err = tx.Exec(query,
+				bid.ID, bid.AuctionID, bid.BidderID, bid.WalletID,
+				bid.Amount, bid.CreatedAt, bid.Accepted, bid.MaxAmount, bid.Automatic,
+				bid.Signature, bid.Message)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Update auction with new highest bid
+		query := `UPDATE auctions SET current_bid = $1, current_bidder_id = $2, proxy_max_bid = $3, 
+				proxy_wallet_id = $4, end_time = $5, extension_count = $6, status = $7, updated_at = $8 
+				WHERE id = $9`
+		_, err = tx.Exec(query, auction.CurrentBid, auction.CurrentBidderID, auction.ProxyMaxBid,
+			auction.ProxyWalletID, auction.EndTime, auction.ExtensionCount, auction.Status, now, auctionID)
+		if err != nil {
+			return err
+		}
+
+		return appendBidEvents(tx, auction, bids, endTime, status)
+	})
+}
+
+// isHighBid reports whether bid is the auction's current high bid
+func isHighBid(auction *models.Auction, bid *models.Bid) bool {
+	return auction.CurrentBid != nil && auction.CurrentBidderID != nil &&
+		*auction.CurrentBid == bid.Amount && *auction.CurrentBidderID == bid.BidderID
+}
+
+// appendBidEvents records the bids placed on an auction, then its extension
+// and the status the bids moved it to, if any
+func appendBidEvents(tx *sqlx.Tx, auction *models.Auction, bids []*models.Bid, endTime time.Time, status models.AuctionStatus) error {
+	err := appendAuctionEvent(tx, auction.ID, models.AuctionEventBidPlaced, auctionState{
+		"bids":              bids,
+		"current_bid":       auction.CurrentBid,
+		"current_bidder_id": auction.CurrentBidderID,
+	}, auctionState{
+		"proxy_max_bid":   auction.ProxyMaxBid,
+		"proxy_wallet_id": auction.ProxyWalletID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !auction.EndTime.Equal(endTime) {
+		err = appendAuctionEvent(tx, auction.ID, models.AuctionEventExtended, auctionState{
+			"end_time":        auction.EndTime,
+			"extension_count": auction.ExtensionCount,
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if auction.Status != status {
+		return appendAuctionEvent(tx, auction.ID, models.StatusEventType(auction.Status),
+			auctionState{"status": auction.Status}, nil)
+	}
+
+	return nil
+}
+
+// GetLeadingBidTotal sums what a user's leading bids placed from a wallet may
+// still cost them, other than on one auction. Proxy bids count at their maximum.
+func (r *AuctionRepository) GetLeadingBidTotal(userID, walletID, excludeAuctionID string) (int64, error) {
+	var total int64
+	query := `SELECT COALESCE(SUM(COALESCE(a.proxy_max_bid, a.current_bid)), 0) FROM auctions a 
+			 WHERE a.current_bidder_id = $1 AND a.id <> $3 AND a.status IN ($4, $5, $6, $7) 
+			 AND (SELECT b.wallet_id FROM bids b WHERE b.auction_id = a.id AND b.bidder_id = $1 
+			      ORDER BY b.created_at DESC LIMIT 1) = $2`
+	err := r.db.GetDB().Get(&total, query, userID, walletID, excludeAuctionID,
+		models.AuctionStatusActive, models.AuctionStatusRevealing, models.AuctionStatusEnded,
+		models.AuctionStatusSettlementFailed)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
 // GetBidsByAuctionID retrieves bids for an auction
 func (r *AuctionRepository) GetBidsByAuctionID(auctionID string) ([]models.Bid, error) {
 	bids := []models.Bid{}
-	query := `SELECT id, auction_id, bidder_id, wallet_id, amount, created_at, accepted, signature 
+	query := `SELECT id, auction_id, bidder_id, wallet_id, amount, created_at, accepted, signature, automatic 
 			 FROM bids 
 			 WHERE auction_id = $1 
 			 ORDER BY amount DESC`
@@ -338,10 +874,29 @@ func (r *AuctionRepository) GetBidsByAuctionID(auctionID string) ([]models.Bid,
 	return bids, nil
 }
 
+// GetSignedBids retrieves the bids of an auction in the order they were
+// placed, with the address of the wallet each was placed from
+func (r *AuctionRepository) GetSignedBids(auctionID string) ([]models.SignedBid, error) {
+	bids := []models.SignedBid{}
+	query := `SELECT b.id, b.bidder_id, w.address AS wallet_address, b.amount, b.created_at, b.automatic, 
+			 b.message, b.signature, b.max_amount 
+			 FROM bids b 
+			 JOIN wallets w ON w.id = b.wallet_id 
+			 WHERE b.auction_id = $1 
+			 ORDER BY b.created_at, b.amount`
+
+	err := r.db.GetDB().Select(&bids, query, auctionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return bids, nil
+}
+
 // GetTopBidsByAuctionID retrieves top N bids for an auction
 func (r *AuctionRepository) GetTopBidsByAuctionID(auctionID string, limit int) ([]models.Bid, error) {
 	bids := []models.Bid{}
-	query := `SELECT id, auction_id, bidder_id, wallet_id, amount, created_at, accepted, signature 
+	query := `SELECT id, auction_id, bidder_id, wallet_id, amount, created_at, accepted, signature, automatic 
 			 FROM bids 
 			 WHERE auction_id = $1 
 			 ORDER BY amount DESC
@@ -358,8 +913,12 @@ func (r *AuctionRepository) GetTopBidsByAuctionID(auctionID string, limit int) (
 // GetActiveAuctions retrieves all active auctions
 func (r *AuctionRepository) GetActiveAuctions() ([]models.Auction, error) {
 	auctions := []models.Auction{}
-	query := `SELECT id, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
-			 current_bid, current_bidder_id, start_time, end_time, status, psbt, created_at, updated_at
+	query := `SELECT id, type, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
+			 current_bid, current_bidder_id, start_time, end_time, status, psbt, 
+			 settlement_psbt, settlement_txid, settlement_wallet_id, extension_window, extension_length,
+			 max_extensions, extension_count, bid_increment, floor_price, price_schedule,
+			 price_step_interval, reveal_end_time, sealed_pricing, cancellation_penalty, 
+			 created_at, updated_at
 			 FROM auctions 
 			 WHERE status = $1 AND end_time > $2
 			 ORDER BY end_time ASC`
@@ -372,19 +931,321 @@ func (r *AuctionRepository) GetActiveAuctions() ([]models.Auction, error) {
 	return auctions, nil
 }
 
+// GetDueDraftAuctions retrieves draft auctions whose start time has passed
+func (r *AuctionRepository) GetDueDraftAuctions(now time.Time) ([]models.Auction, error) {
+	auctions := []models.Auction{}
+	query := `SELECT id, type, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
+			 current_bid, current_bidder_id, start_time, end_time, status, psbt, 
+			 settlement_psbt, settlement_txid, settlement_wallet_id, extension_window, extension_length,
+			 max_extensions, extension_count, bid_increment, floor_price, price_schedule,
+			 price_step_interval, reveal_end_time, sealed_pricing, cancellation_penalty, 
+			 created_at, updated_at
+			 FROM auctions 
+			 WHERE status = $1 AND start_time <= $2
+			 ORDER BY start_time ASC`
+
+	err := r.db.GetDB().Select(&auctions, query, models.AuctionStatusDraft, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return auctions, nil
+}
+
 // GetEndedAuctions retrieves auctions that have ended but not yet finalized
-func (r *AuctionRepository) GetEndedAuctions() ([]models.Auction, error) {
+func (r *AuctionRepository) GetEndedAuctions(now time.Time) ([]models.Auction, error) {
 	auctions := []models.Auction{}
-	query := `SELECT id, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
-			 current_bid, current_bidder_id, start_time, end_time, status, psbt, created_at, updated_at
+	query := `SELECT id, type, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
+			 current_bid, current_bidder_id, start_time, end_time, status, psbt, 
+			 settlement_psbt, settlement_txid, settlement_wallet_id, extension_window, extension_length,
+			 max_extensions, extension_count, bid_increment, floor_price, price_schedule,
+			 price_step_interval, reveal_end_time, sealed_pricing, cancellation_penalty, 
+			 created_at, updated_at
 			 FROM auctions 
 			 WHERE status = $1 AND end_time <= $2
 			 ORDER BY end_time ASC`
 
-	err := r.db.GetDB().Select(&auctions, query, models.AuctionStatusActive, time.Now())
+	err := r.db.GetDB().Select(&auctions, query, models.AuctionStatusActive, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return auctions, nil
+}
+
+// GetRevealClosedAuctions retrieves sealed-bid auctions whose reveal phase has closed
+func (r *AuctionRepository) GetRevealClosedAuctions(now time.Time) ([]models.Auction, error) {
+	auctions := []models.Auction{}
+	query := `SELECT id, type, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
+			 current_bid, current_bidder_id, start_time, end_time, status, psbt, 
+			 settlement_psbt, settlement_txid, settlement_wallet_id, extension_window, extension_length,
+			 max_extensions, extension_count, bid_increment, floor_price, price_schedule,
+			 price_step_interval, reveal_end_time, sealed_pricing, cancellation_penalty, 
+			 created_at, updated_at
+			 FROM auctions 
+			 WHERE status = $1 AND reveal_end_time <= $2
+			 ORDER BY reveal_end_time ASC`
+
+	err := r.db.GetDB().Select(&auctions, query, models.AuctionStatusRevealing, now)
 	if err != nil {
 		return nil, err
 	}
 
 	return auctions, nil
 }
+
+// GetUnsettledAuctions retrieves auctions that ended before the settlement
+// deadline and whose winner has not broadcast a settlement
+func (r *AuctionRepository) GetUnsettledAuctions(endedBefore time.Time) ([]models.Auction, error) {
+	auctions := []models.Auction{}
+	query := `SELECT id, type, nft_id, seller_wallet_id, start_price, reserve_price, buy_now_price, 
+			 current_bid, current_bidder_id, start_time, end_time, status, psbt, 
+			 settlement_psbt, settlement_txid, settlement_wallet_id, extension_window, extension_length,
+			 max_extensions, extension_count, bid_increment, floor_price, price_schedule,
+			 price_step_interval, reveal_end_time, sealed_pricing, cancellation_penalty, 
+			 created_at, updated_at
+			 FROM auctions 
+			 WHERE status IN ($1, $2) AND end_time <= $3
+			 ORDER BY end_time ASC`
+
+	err := r.db.GetDB().Select(&auctions, query,
+		models.AuctionStatusEnded, models.AuctionStatusSettlementFailed, endedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	return auctions, nil
+}
+
+// ExpireSettlement cancels an auction whose winner did not settle before the
+// deadline and releases its NFTs. It reports whether the auction was still
+// waiting for the winner.
+func (r *AuctionRepository) ExpireSettlement(id string, endedBefore, now time.Time) (bool, error) {
+	var expired bool
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		query := `UPDATE auctions SET status = $1, updated_at = $2 
+				 WHERE id = $3 AND status IN ($4, $5) AND end_time <= $6`
+		result, err := tx.Exec(query, models.AuctionStatusCancelled, now, id,
+			models.AuctionStatusEnded, models.AuctionStatusSettlementFailed, endedBefore)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil || rows == 0 {
+			return err
+		}
+		expired = true
+
+		err = appendAuctionEvent(tx, id, models.AuctionEventCancelled,
+			auctionState{"status": models.AuctionStatusCancelled}, nil)
+		if err != nil {
+			return err
+		}
+
+		// Remove the auction_id from every NFT of the auction
+		query = `UPDATE nfts SET auction_id = NULL, updated_at = $1 
+				WHERE auction_id = $2`
+		_, err = tx.Exec(query, now, id)
+		return err
+	})
+
+	return expired, err
+}
+
+// CommitBid locks the auction row, runs validate against its current state and
+// records the bidder's sealed bid commitment, replacing an earlier one
+func (r *AuctionRepository) CommitBid(commitment *models.BidCommitment, validate func(auction *models.Auction) error) error {
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		auction, err := lockAuction(tx, commitment.AuctionID)
+		if err != nil {
+			return err
+		}
+
+		if err := validate(auction); err != nil {
+			return err
+		}
+
+		if commitment.ID == "" {
+			commitment.ID = uuid.New().String()
+		}
+		commitment.CreatedAt = time.Now()
+		commitment.Status = models.CommitmentStatusCommitted
+
+		query := `INSERT INTO bid_commitments (id, auction_id, bidder_id, wallet_id, commitment, status, created_at) 
+				 VALUES ($1, $2, $3, $4, $5, $6, $7) 
+				 ON CONFLICT (auction_id, bidder_id) DO UPDATE SET wallet_id = EXCLUDED.wallet_id, 
+				 commitment = EXCLUDED.commitment, created_at = EXCLUDED.created_at 
+				 RETURNING id`
+
+		err = tx.Get(&commitment.ID, query,
+			commitment.ID, commitment.AuctionID, commitment.BidderID, commitment.WalletID,
+			commitment.Commitment, commitment.Status, commitment.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		return appendAuctionEvent(tx, commitment.AuctionID, models.AuctionEventBidCommitted,
+			auctionState{"bidder_id": commitment.BidderID}, nil)
+	})
+}
+
+// GetBidCommitment retrieves a bidder's sealed bid commitment on an auction
+func (r *AuctionRepository) GetBidCommitment(auctionID, bidderID string) (*models.BidCommitment, error) {
+	commitment := &models.BidCommitment{}
+	query := `SELECT id, auction_id, bidder_id, wallet_id, commitment, amount, status, created_at, revealed_at 
+			 FROM bid_commitments WHERE auction_id = $1 AND bidder_id = $2`
+
+	err := r.db.GetDB().Get(commitment, query, auctionID, bidderID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return commitment, nil
+}
+
+// RevealBid locks the auction row and the bidder's commitment, runs reveal
+// against them and saves the revealed amount
+func (r *AuctionRepository) RevealBid(auctionID, bidderID string, reveal func(auction *models.Auction, commitment *models.BidCommitment) error) (*models.BidCommitment, error) {
+	commitment := &models.BidCommitment{}
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		auction, err := lockAuction(tx, auctionID)
+		if err != nil {
+			return err
+		}
+
+		query := `SELECT id, auction_id, bidder_id, wallet_id, commitment, amount, status, created_at, revealed_at 
+				 FROM bid_commitments WHERE auction_id = $1 AND bidder_id = $2`
+		err = tx.Get(commitment, query, auctionID, bidderID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("no sealed bid to reveal")
+			}
+			return err
+		}
+
+		if err := reveal(auction, commitment); err != nil {
+			return err
+		}
+
+		query = `UPDATE bid_commitments SET amount = $1, status = $2, revealed_at = $3 WHERE id = $4`
+		_, err = tx.Exec(query, commitment.Amount, commitment.Status, commitment.RevealedAt, commitment.ID)
+		if err != nil {
+			return err
+		}
+
+		// Revealed amounts stay secret until the auction closes
+		return appendAuctionEvent(tx, auctionID, models.AuctionEventBidRevealed,
+			auctionState{"bidder_id": bidderID}, auctionState{"amount": commitment.Amount})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commitment, nil
+}
+
+// CloseSealedAuction locks a sealed-bid auction in its reveal phase, forfeits
+// the unrevealed commitments and runs decide on the revealed ones. The winning
+// bid decide returns is recorded and the auction ends; without one the auction
+// is cancelled and releases its NFT. It returns the new status, or an empty
+// status if the auction was no longer revealing.
+func (r *AuctionRepository) CloseSealedAuction(id string, now time.Time, decide func(auction *models.Auction, revealed []models.BidCommitment) (*models.Bid, error)) (models.AuctionStatus, error) {
+	var status models.AuctionStatus
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		auction, err := lockAuction(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if auction.Status != models.AuctionStatusRevealing {
+			return nil
+		}
+
+		// Forfeit the commitments that were never revealed
+		query := `UPDATE bid_commitments SET status = $1 WHERE auction_id = $2 AND status = $3`
+		_, err = tx.Exec(query, models.CommitmentStatusForfeited, id, models.CommitmentStatusCommitted)
+		if err != nil {
+			return err
+		}
+
+		// Earlier commitments win ties
+		revealed := []models.BidCommitment{}
+		query = `SELECT id, auction_id, bidder_id, wallet_id, commitment, amount, status, created_at, revealed_at 
+				FROM bid_commitments 
+				WHERE auction_id = $1 AND status = $2 
+				ORDER BY amount DESC, created_at ASC`
+		err = tx.Select(&revealed, query, id, models.CommitmentStatusRevealed)
+		if err != nil {
+			return err
+		}
+
+		bid, err := decide(auction, revealed)
+		if err != nil {
+			return err
+		}
+
+		if bid == nil {
+			status = models.AuctionStatusCancelled
+
+			query = `UPDATE auctions SET status = $1, updated_at = $2 WHERE id = $3`
+			_, err = tx.Exec(query, status, now, id)
+			if err != nil {
+				return err
+			}
+
+			err = appendAuctionEvent(tx, id, models.AuctionEventCancelled, auctionState{"status": status}, nil)
+			if err != nil {
+				return err
+			}
+
+			// Remove the auction_id from NFT
+			query = `UPDATE nfts SET auction_id = NULL, updated_at = $1 
+					WHERE auction_id = $2`
+			_, err = tx.Exec(query, now, id)
+			return err
+		}
+
+		status = models.AuctionStatusEnded
+
+		if bid.ID == "" {
+			bid.ID = uuid.New().String()
+		}
+		bid.CreatedAt = now
+		bid.Accepted = true
+
+		// Insert the winning bid
+		query = `INSERT INTO bids (id, auction_id, bidder_id, wallet_id, amount, created_at, accepted, 
+				 max_amount, automatic, signature, message) 
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+		_, err = tx.Exec(query,
+			bid.ID, bid.AuctionID, bid.BidderID, bid.WalletID,
+			bid.Amount, bid.CreatedAt, bid.Accepted, bid.MaxAmount, bid.Automatic,
+			bid.Signature, bid.Message)
+		if err != nil {
+			return err
+		}
+
+		query = `UPDATE auctions SET current_bid = $1, current_bidder_id = $2, status = $3, updated_at = $4 
+				WHERE id = $5`
+		_, err = tx.Exec(query, bid.Amount, bid.BidderID, status, now, id)
+		if err != nil {
+			return err
+		}
+
+		return appendAuctionEvent(tx, id, models.AuctionEventEnded, auctionState{
+			"bids":              []*models.Bid{bid},
+			"current_bid":       bid.Amount,
+			"current_bidder_id": bid.BidderID,
+			"status":            status,
+		}, nil)
+	})
+
+	return status, err
+}
@@ -22,6 +22,11 @@ func NewDatabase(cfg config.DatabaseConfig) (*Database, error) {
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name,
 	)
 
+	return NewDatabaseFromDSN(connStr)
+}
+
+// NewDatabaseFromDSN creates a new database connection from a connection string
+func NewDatabaseFromDSN(connStr string) (*Database, error) {
 	// Connect to the database
 	db, err := sqlx.Connect("postgres", connStr)
 	if err != nil {
@@ -2,6 +2,8 @@ package store
 
 import (
 	"database/sql"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,7 +26,8 @@ func NewNFTRepository(db *Database) *NFTRepository {
 func (r *NFTRepository) GetByID(id string) (*models.NFT, error) {
 	nft := &models.NFT{}
 	query := `SELECT id, wallet_id, token_id, inscription_id, collection, title, 
-			  description, image_url, content_url, metadata, created_at, updated_at, auction_id
+			  description, image_url, content_url, metadata, created_at, updated_at, auction_id, location,
+			  content_type, genesis_height, sat_number
 			  FROM nfts WHERE id = $1`
 
 	err := r.db.GetDB().Get(nft, query, id)
@@ -38,6 +41,25 @@ func (r *NFTRepository) GetByID(id string) (*models.NFT, error) {
 	return nft, nil
 }
 
+// GetInscribedOutpoints retrieves the outpoints, txid:vout, of the wallet's
+// UTXOs known to hold an inscription
+func (r *NFTRepository) GetInscribedOutpoints(walletID string) ([]string, error) {
+	var locations []string
+	query := `SELECT location FROM nfts WHERE wallet_id = $1 AND location <> ''`
+	if err := r.db.GetDB().Select(&locations, query, walletID); err != nil {
+		return nil, err
+	}
+
+	outpoints := make([]string, 0, len(locations))
+	for _, location := range locations {
+		if i := strings.LastIndex(location, ":"); i > 0 {
+			outpoints = append(outpoints, location[:i])
+		}
+	}
+
+	return outpoints, nil
+}
+
 // GetByWalletID retrieves NFTs by wallet ID
 func (r *NFTRepository) GetByWalletID(walletID string, params models.NFTParams) ([]models.NFT, int, error) {
 	nfts := []models.NFT{}
@@ -66,7 +88,7 @@ func (r *NFTRepository) GetByWalletID(walletID string, params models.NFTParams)
 
 	// Add collection filter if provided
 	if params.Collection != "" {
-		baseQuery += ` AND collection = $` + string('0'+argCount)
+		baseQuery += ` AND collection = $` + strconv.Itoa(argCount)
 		args = append(args, params.Collection)
 		argCount++
 	}
@@ -82,9 +104,10 @@ func (r *NFTRepository) GetByWalletID(walletID string, params models.NFTParams)
 	// Get paginated results
 	offset := (params.Page - 1) * params.PageSize
 	selectQuery := `SELECT id, wallet_id, token_id, inscription_id, collection, title, 
-				   description, image_url, content_url, metadata, created_at, updated_at, auction_id ` +
-		baseQuery + ` ORDER BY created_at DESC LIMIT $` + string('0'+argCount) +
-		` OFFSET $` + string('0'+argCount+1)
+				   description, image_url, content_url, metadata, created_at, updated_at, auction_id, location,
+				   content_type, genesis_height, sat_number ` +
+		baseQuery + ` ORDER BY created_at DESC LIMIT $` + strconv.Itoa(argCount) +
+		` OFFSET $` + strconv.Itoa(argCount+1)
 	args = append(args, params.PageSize, offset)
 
 	err = r.db.GetDB().Select(&nfts, selectQuery, args...)
@@ -125,7 +148,7 @@ func (r *NFTRepository) GetByUserID(userID string, params models.NFTParams) ([]m
 
 	// Add collection filter if provided
 	if params.Collection != "" {
-		baseQuery += ` AND n.collection = $` + string('0'+argCount)
+		baseQuery += ` AND n.collection = $` + strconv.Itoa(argCount)
 		args = append(args, params.Collection)
 		argCount++
 	}
@@ -141,9 +164,10 @@ func (r *NFTRepository) GetByUserID(userID string, params models.NFTParams) ([]m
 	// Get paginated results
 	offset := (params.Page - 1) * params.PageSize
 	selectQuery := `SELECT n.id, n.wallet_id, n.token_id, n.inscription_id, n.collection, n.title, 
-				   n.description, n.image_url, n.content_url, n.metadata, n.created_at, n.updated_at, n.auction_id ` +
-		baseQuery + ` ORDER BY n.created_at DESC LIMIT $` + string('0'+argCount) +
-		` OFFSET $` + string('0'+argCount+1)
+				   n.description, n.image_url, n.content_url, n.metadata, n.created_at, n.updated_at, n.auction_id, n.location,
+				   n.content_type, n.genesis_height, n.sat_number ` +
+		baseQuery + ` ORDER BY n.created_at DESC LIMIT $` + strconv.Itoa(argCount) +
+		` OFFSET $` + strconv.Itoa(argCount+1)
 	args = append(args, params.PageSize, offset)
 
 	err = r.db.GetDB().Select(&nfts, selectQuery, args...)
@@ -164,13 +188,15 @@ func (r *NFTRepository) Create(nft *models.NFT) error {
 	nft.UpdatedAt = now
 
 	query := `INSERT INTO nfts (id, wallet_id, token_id, inscription_id, collection, title, 
-			  description, image_url, content_url, metadata, created_at, updated_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+			  description, image_url, content_url, metadata, created_at, updated_at, location,
+			  content_type, genesis_height, sat_number) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
 
 	_, err := r.db.GetDB().Exec(query,
 		nft.ID, nft.WalletID, nft.TokenID, nft.InscriptionID, nft.Collection,
 		nft.Title, nft.Description, nft.ImageURL, nft.ContentURL,
-		nft.Metadata, nft.CreatedAt, nft.UpdatedAt)
+		nft.Metadata, nft.CreatedAt, nft.UpdatedAt, nft.Location,
+		nft.ContentType, nft.GenesisHeight, nft.SatNumber)
 
 	return err
 }
@@ -181,13 +207,15 @@ func (r *NFTRepository) Update(nft *models.NFT) error {
 
 	query := `UPDATE nfts SET wallet_id = $1, token_id = $2, inscription_id = $3, 
 			  collection = $4, title = $5, description = $6, image_url = $7, 
-			  content_url = $8, metadata = $9, updated_at = $10, auction_id = $11
-			  WHERE id = $12`
+			  content_url = $8, metadata = $9, updated_at = $10, auction_id = $11, location = $12,
+			  content_type = $13, genesis_height = $14, sat_number = $15
+			  WHERE id = $16`
 
 	_, err := r.db.GetDB().Exec(query,
 		nft.WalletID, nft.TokenID, nft.InscriptionID, nft.Collection,
 		nft.Title, nft.Description, nft.ImageURL, nft.ContentURL,
-		nft.Metadata, nft.UpdatedAt, nft.AuctionID, nft.ID)
+		nft.Metadata, nft.UpdatedAt, nft.AuctionID, nft.Location,
+		nft.ContentType, nft.GenesisHeight, nft.SatNumber, nft.ID)
 
 	return err
 }
@@ -198,3 +226,28 @@ func (r *NFTRepository) UpdateAuctionID(nftID string, auctionID *string) error {
 	_, err := r.db.GetDB().Exec(query, auctionID, time.Now(), nftID)
 	return err
 }
+
+// GetCollectionStats summarizes the listings and collection offers of a
+// collection. Lot listings are priced as a whole and left out of the floor.
+func (r *NFTRepository) GetCollectionStats(collection string, now time.Time) (*models.CollectionStats, error) {
+	stats := &models.CollectionStats{}
+	query := `SELECT $1::TEXT AS collection,
+			  (SELECT COUNT(*) FROM nfts WHERE collection = $1) AS item_count,
+			  (SELECT COUNT(*) FROM nfts n JOIN auctions a ON n.auction_id = a.id 
+			   WHERE n.collection = $1 AND a.type = $2 AND a.status = $3) AS listed_count,
+			  (SELECT MIN(a.start_price) FROM auctions a JOIN nfts n ON a.nft_id = n.id 
+			   WHERE n.collection = $1 AND a.type = $2 AND a.status = $3 
+			   AND NOT EXISTS (SELECT 1 FROM auction_lots l WHERE l.auction_id = a.id)) AS floor_price,
+			  (SELECT MAX(price) FROM collection_offers 
+			   WHERE collection = $1 AND status = $4 AND remaining > 0 AND expires_at > $5) AS best_offer,
+			  (SELECT COALESCE(SUM(remaining), 0) FROM collection_offers 
+			   WHERE collection = $1 AND status = $4 AND expires_at > $5) AS offer_count`
+
+	err := r.db.GetDB().Get(stats, query, collection, models.AuctionTypeFixedPrice, models.AuctionStatusActive,
+		models.CollectionOfferStatusOpen, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
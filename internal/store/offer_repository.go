@@ -0,0 +1,577 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/satonic/satonic-api/internal/models"
+)
+
+// OfferRepository handles database operations related to offers
+type OfferRepository struct {
+	db *Database
+}
+
+// NewOfferRepository creates a new OfferRepository
+func NewOfferRepository(db *Database) *OfferRepository {
+	return &OfferRepository{
+		db: db,
+	}
+}
+
+// GetByID retrieves an offer by ID
+func (r *OfferRepository) GetByID(id string) (*models.Offer, error) {
+	offer := &models.Offer{}
+	query := `SELECT id, nft_id, offerer_id, wallet_id, receive_wallet_id, owner_wallet_id,
+			  collection_offer_id, amount, location, psbt, settlement_txid, status, reason, expires_at,
+			  accepted_at, created_at, updated_at
+			  FROM offers WHERE id = $1`
+
+	err := r.db.GetDB().Get(offer, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+// List retrieves offers with filtering and pagination
+func (r *OfferRepository) List(params models.OfferParams) ([]models.Offer, int, error) {
+	offers := []models.Offer{}
+
+	// Default pagination values
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 10
+	}
+
+	// Base query
+	baseQuery := `FROM offers o`
+	whereClause := ``
+	args := []interface{}{}
+	argCount := 1
+
+	// Add NFT filter if provided
+	if params.NFTID != "" {
+		if whereClause == "" {
+			whereClause = ` WHERE`
+		} else {
+			whereClause += ` AND`
+		}
+		whereClause += ` o.nft_id = $` + strconv.Itoa(argCount)
+		args = append(args, params.NFTID)
+		argCount++
+	}
+
+	// Add status filter if provided
+	if params.Status != "" {
+		if whereClause == "" {
+			whereClause = ` WHERE`
+		} else {
+			whereClause += ` AND`
+		}
+		whereClause += ` o.status = $` + strconv.Itoa(argCount)
+		args = append(args, params.Status)
+		argCount++
+	}
+
+	// Add offerer filter if provided
+	if params.OffererID != "" {
+		if whereClause == "" {
+			whereClause = ` WHERE`
+		} else {
+			whereClause += ` AND`
+		}
+		whereClause += ` o.offerer_id = $` + strconv.Itoa(argCount)
+		args = append(args, params.OffererID)
+		argCount++
+	}
+
+	// Add owner filter if provided
+	if params.OwnerID != "" {
+		if whereClause == "" {
+			whereClause = ` WHERE`
+		} else {
+			whereClause += ` AND`
+		}
+		// Join with wallets to filter by owner user ID
+		baseQuery += ` JOIN wallets w ON o.owner_wallet_id = w.id`
+		whereClause += ` w.user_id = $` + strconv.Itoa(argCount)
+		args = append(args, params.OwnerID)
+		argCount++
+	}
+
+	// Complete the query
+	baseQuery += whereClause
+
+	// Count total matching records
+	var total int
+	countQuery := `SELECT COUNT(*) ` + baseQuery
+	err := r.db.GetDB().Get(&total, countQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results, highest offers first
+	offset := (params.Page - 1) * params.PageSize
+	selectQuery := `SELECT o.id, o.nft_id, o.offerer_id, o.wallet_id, o.receive_wallet_id, o.owner_wallet_id,
+				   o.collection_offer_id, o.amount, o.location, o.psbt, o.settlement_txid, o.status, o.reason,
+				   o.expires_at, o.accepted_at, o.created_at, o.updated_at ` +
+		baseQuery + ` ORDER BY o.amount DESC, o.created_at ASC LIMIT $` + strconv.Itoa(argCount) +
+		` OFFSET $` + strconv.Itoa(argCount+1)
+	args = append(args, params.PageSize, offset)
+
+	err = r.db.GetDB().Select(&offers, selectQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return offers, total, nil
+}
+
+// Create creates a new offer
+func (r *OfferRepository) Create(offer *models.Offer) error {
+	if offer.ID == "" {
+		offer.ID = uuid.New().String()
+	}
+	now := time.Now()
+	offer.CreatedAt = now
+	offer.UpdatedAt = now
+
+	if offer.Status == "" {
+		offer.Status = models.OfferStatusPending
+	}
+
+	return insertOffer(r.db.GetDB(), offer)
+}
+
+// insertOffer inserts an offer with any database handle
+func insertOffer(db sqlx.Execer, offer *models.Offer) error {
+	query := `INSERT INTO offers (id, nft_id, offerer_id, wallet_id, receive_wallet_id, owner_wallet_id,
+			 collection_offer_id, amount, location, psbt, status, expires_at, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	_, err := db.Exec(query,
+		offer.ID, offer.NFTID, offer.OffererID, offer.WalletID, offer.ReceiveWalletID,
+		offer.OwnerWalletID, offer.CollectionOfferID, offer.Amount, offer.Location, offer.PSBT,
+		offer.Status, offer.ExpiresAt, offer.CreatedAt, offer.UpdatedAt)
+
+	return err
+}
+
+// Open stores the signed PSBT of a pending offer and opens it. Fills of a
+// collection offer were made by the owner and are accepted right away. It
+// reports whether the offer was still pending.
+func (r *OfferRepository) Open(id, psbt string) (bool, error) {
+	query := `UPDATE offers SET 
+			 status = CASE WHEN collection_offer_id IS NULL THEN $1 ELSE $2 END, 
+			 accepted_at = CASE WHEN collection_offer_id IS NULL THEN NULL ELSE $3 END, 
+			 psbt = $4, updated_at = $3 
+			 WHERE id = $5 AND status = $6`
+	result, err := r.db.GetDB().Exec(query, models.OfferStatusOpen, models.OfferStatusAccepted, time.Now(),
+		psbt, id, models.OfferStatusPending)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// Accept marks an open offer that has not expired as accepted. It reports
+// whether the offer could be accepted.
+func (r *OfferRepository) Accept(id string, now time.Time) (bool, error) {
+	query := `UPDATE offers SET status = $1, accepted_at = $2, updated_at = $2
+			 WHERE id = $3 AND status = $4 AND expires_at > $2`
+	result, err := r.db.GetDB().Exec(query, models.OfferStatusAccepted, now, id, models.OfferStatusOpen)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// TransitionStatus moves an offer that is still in one of the given statuses
+// to another status, recording why. A lapsed fill hands its item back to its
+// collection offer. It reports whether the offer moved.
+func (r *OfferRepository) TransitionStatus(id string, to models.OfferStatus, reason string, from ...models.OfferStatus) (bool, error) {
+	var moved bool
+
+	err := r.db.Transaction(func(tx *sqlx.Tx) error {
+		query, args, err := sqlx.In(`UPDATE offers SET status = ?, reason = ?, updated_at = ? 
+				 WHERE id = ? AND status IN (?) 
+				 RETURNING collection_offer_id`, to, reason, time.Now(), id, from)
+		if err != nil {
+			return err
+		}
+
+		var collectionOfferID *string
+		err = tx.Get(&collectionOfferID, tx.Rebind(query), args...)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+		moved = true
+
+		if collectionOfferID != nil && to != models.OfferStatusSettled {
+			return restoreCollectionOffer(tx, *collectionOfferID)
+		}
+
+		return nil
+	})
+
+	return moved, err
+}
+
+// ExpireOffers expires the live offers whose expiry has passed
+func (r *OfferRepository) ExpireOffers(now time.Time) error {
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		var collectionOfferIDs []*string
+		query := `UPDATE offers SET status = $1, updated_at = $2 
+				 WHERE status IN ($3, $4, $5) AND expires_at <= $2 
+				 RETURNING collection_offer_id`
+		err := tx.Select(&collectionOfferIDs, query, models.OfferStatusExpired, now,
+			models.OfferStatusPending, models.OfferStatusOpen, models.OfferStatusAccepted)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range collectionOfferIDs {
+			if id == nil {
+				continue
+			}
+			if err := restoreCollectionOffer(tx, *id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// restoreCollectionOffer hands the item of a lapsed fill back to its
+// collection offer, reopening it if it was filled
+func restoreCollectionOffer(tx *sqlx.Tx, id string) error {
+	query := `UPDATE collection_offers SET remaining = remaining + 1, 
+			 status = CASE WHEN status = $1 THEN $2 ELSE status END, updated_at = $3 
+			 WHERE id = $4`
+	_, err := tx.Exec(query, models.CollectionOfferStatusFilled, models.CollectionOfferStatusOpen,
+		time.Now(), id)
+	return err
+}
+
+// GetFundedOffers retrieves the open and accepted offers
+func (r *OfferRepository) GetFundedOffers() ([]models.Offer, error) {
+	return r.getByStatus(models.OfferStatusOpen, models.OfferStatusAccepted)
+}
+
+// GetSettlingOffers retrieves offers whose settlement transaction is unconfirmed
+func (r *OfferRepository) GetSettlingOffers() ([]models.Offer, error) {
+	return r.getByStatus(models.OfferStatusSettling)
+}
+
+// getByStatus retrieves the offers in any of the given statuses, oldest
+// update first
+func (r *OfferRepository) getByStatus(statuses ...models.OfferStatus) ([]models.Offer, error) {
+	offers := []models.Offer{}
+	query, args, err := sqlx.In(`SELECT id, nft_id, offerer_id, wallet_id, receive_wallet_id, owner_wallet_id,
+			 collection_offer_id, amount, location, psbt, settlement_txid, status, reason, expires_at,
+			 accepted_at, created_at, updated_at
+			 FROM offers
+			 WHERE status IN (?)
+			 ORDER BY updated_at ASC`, statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.GetDB().Select(&offers, r.db.GetDB().Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+// StartSettlement records the broadcast settlement transaction of an accepted offer
+func (r *OfferRepository) StartSettlement(id, txid string) error {
+	query := `UPDATE offers SET status = $1, settlement_txid = $2, updated_at = $3
+			 WHERE id = $4 AND status = $5`
+	result, err := r.db.GetDB().Exec(query, models.OfferStatusSettling, txid, time.Now(),
+		id, models.OfferStatusAccepted)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("offer is not accepted")
+	}
+
+	return nil
+}
+
+// CompleteSettlement marks an offer as settled and moves the NFT to the
+// offerer's wallet
+func (r *OfferRepository) CompleteSettlement(offerID, nftID, walletID, location string) error {
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		now := time.Now()
+
+		// Update offer status
+		query := `UPDATE offers SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4`
+		result, err := tx.Exec(query, models.OfferStatusSettled, now, offerID, models.OfferStatusSettling)
+		if err != nil {
+			return err
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return fmt.Errorf("offer is not settling")
+		}
+
+		// Transfer the NFT
+		query = `UPDATE nfts SET wallet_id = $1, location = $2, updated_at = $3 WHERE id = $4`
+		_, err = tx.Exec(query, walletID, location, now, nftID)
+		return err
+	})
+}
+
+// GetCollectionOfferByID retrieves a collection offer by ID
+func (r *OfferRepository) GetCollectionOfferByID(id string) (*models.CollectionOffer, error) {
+	collectionOffer := &models.CollectionOffer{}
+	query := `SELECT id, collection, offerer_id, wallet_id, receive_wallet_id, price, quantity, remaining,
+			  status, expires_at, created_at, updated_at
+			  FROM collection_offers WHERE id = $1`
+
+	err := r.db.GetDB().Get(collectionOffer, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return collectionOffer, nil
+}
+
+// ListCollectionOffers retrieves collection offers with filtering and pagination
+func (r *OfferRepository) ListCollectionOffers(params models.CollectionOfferParams) ([]models.CollectionOffer, int, error) {
+	collectionOffers := []models.CollectionOffer{}
+
+	// Default pagination values
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = 10
+	}
+
+	// Base query
+	baseQuery := `FROM collection_offers`
+	whereClause := ``
+	args := []interface{}{}
+	argCount := 1
+
+	// Add collection filter if provided
+	if params.Collection != "" {
+		if whereClause == "" {
+			whereClause = ` WHERE`
+		} else {
+			whereClause += ` AND`
+		}
+		whereClause += ` collection = $` + strconv.Itoa(argCount)
+		args = append(args, params.Collection)
+		argCount++
+	}
+
+	// Add status filter if provided
+	if params.Status != "" {
+		if whereClause == "" {
+			whereClause = ` WHERE`
+		} else {
+			whereClause += ` AND`
+		}
+		whereClause += ` status = $` + strconv.Itoa(argCount)
+		args = append(args, params.Status)
+		argCount++
+	}
+
+	// Add offerer filter if provided
+	if params.OffererID != "" {
+		if whereClause == "" {
+			whereClause = ` WHERE`
+		} else {
+			whereClause += ` AND`
+		}
+		whereClause += ` offerer_id = $` + strconv.Itoa(argCount)
+		args = append(args, params.OffererID)
+		argCount++
+	}
+
+	// Complete the query
+	baseQuery += whereClause
+
+	// Count total matching records
+	var total int
+	countQuery := `SELECT COUNT(*) ` + baseQuery
+	err := r.db.GetDB().Get(&total, countQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated results, best price first
+	offset := (params.Page - 1) * params.PageSize
+	selectQuery := `SELECT id, collection, offerer_id, wallet_id, receive_wallet_id, price, quantity, remaining,
+				   status, expires_at, created_at, updated_at ` +
+		baseQuery + ` ORDER BY price DESC, created_at ASC LIMIT $` + strconv.Itoa(argCount) +
+		` OFFSET $` + strconv.Itoa(argCount+1)
+	args = append(args, params.PageSize, offset)
+
+	err = r.db.GetDB().Select(&collectionOffers, selectQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return collectionOffers, total, nil
+}
+
+// CreateCollectionOffer creates a new collection offer
+func (r *OfferRepository) CreateCollectionOffer(collectionOffer *models.CollectionOffer) error {
+	if collectionOffer.ID == "" {
+		collectionOffer.ID = uuid.New().String()
+	}
+	now := time.Now()
+	collectionOffer.CreatedAt = now
+	collectionOffer.UpdatedAt = now
+	collectionOffer.Remaining = collectionOffer.Quantity
+
+	if collectionOffer.Status == "" {
+		collectionOffer.Status = models.CollectionOfferStatusOpen
+	}
+
+	query := `INSERT INTO collection_offers (id, collection, offerer_id, wallet_id, receive_wallet_id, price,
+			 quantity, remaining, status, expires_at, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	_, err := r.db.GetDB().Exec(query,
+		collectionOffer.ID, collectionOffer.Collection, collectionOffer.OffererID, collectionOffer.WalletID,
+		collectionOffer.ReceiveWalletID, collectionOffer.Price, collectionOffer.Quantity,
+		collectionOffer.Remaining, collectionOffer.Status, collectionOffer.ExpiresAt,
+		collectionOffer.CreatedAt, collectionOffer.UpdatedAt)
+
+	return err
+}
+
+// FillCollectionOffer takes one item of a collection offer for a fill and
+// records the fill's offer. The validate callback runs with the collection
+// offer locked and may reject the fill, as does a live fill of the same NFT.
+func (r *OfferRepository) FillCollectionOffer(offer *models.Offer, validate func(collectionOffer *models.CollectionOffer) error) error {
+	if offer.CollectionOfferID == nil {
+		return fmt.Errorf("offer is not a collection offer fill")
+	}
+
+	return r.db.Transaction(func(tx *sqlx.Tx) error {
+		collectionOffer := &models.CollectionOffer{}
+		query := `SELECT id, collection, offerer_id, wallet_id, receive_wallet_id, price, quantity, remaining,
+				 status, expires_at, created_at, updated_at
+				 FROM collection_offers WHERE id = $1 FOR UPDATE`
+		err := tx.Get(collectionOffer, query, *offer.CollectionOfferID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("collection offer not found")
+			}
+			return err
+		}
+
+		if err := validate(collectionOffer); err != nil {
+			return err
+		}
+
+		// An NFT takes a single item until its fill lapses
+		var filling bool
+		query = `SELECT EXISTS (SELECT 1 FROM offers 
+				 WHERE collection_offer_id = $1 AND nft_id = $2 AND status IN ($3, $4, $5, $6))`
+		err = tx.Get(&filling, query, collectionOffer.ID, offer.NFTID,
+			models.OfferStatusPending, models.OfferStatusOpen, models.OfferStatusAccepted, models.OfferStatusSettling)
+		if err != nil {
+			return err
+		}
+
+		if filling {
+			return fmt.Errorf("NFT already fills this collection offer")
+		}
+
+		// Take one item, the offer is filled once none remain
+		query = `UPDATE collection_offers SET remaining = remaining - 1, 
+				 status = CASE WHEN remaining = 1 THEN $1 ELSE status END, updated_at = $2 
+				 WHERE id = $3`
+		_, err = tx.Exec(query, models.CollectionOfferStatusFilled, time.Now(), collectionOffer.ID)
+		if err != nil {
+			return err
+		}
+
+		if offer.ID == "" {
+			offer.ID = uuid.New().String()
+		}
+		now := time.Now()
+		offer.CreatedAt = now
+		offer.UpdatedAt = now
+		offer.ExpiresAt = collectionOffer.ExpiresAt
+
+		return insertOffer(tx, offer)
+	})
+}
+
+// CancelCollectionOffer cancels an open or filled collection offer. Fills
+// already made are left to settle. It reports whether the offer was cancelled.
+func (r *OfferRepository) CancelCollectionOffer(id string) (bool, error) {
+	query := `UPDATE collection_offers SET status = $1, updated_at = $2 
+			 WHERE id = $3 AND status IN ($4, $5)`
+	result, err := r.db.GetDB().Exec(query, models.CollectionOfferStatusCancelled, time.Now(), id,
+		models.CollectionOfferStatusOpen, models.CollectionOfferStatusFilled)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ExpireCollectionOffers expires the open collection offers whose expiry has passed
+func (r *OfferRepository) ExpireCollectionOffers(now time.Time) error {
+	query := `UPDATE collection_offers SET status = $1, updated_at = $2 
+			 WHERE status = $3 AND expires_at <= $2`
+	_, err := r.db.GetDB().Exec(query, models.CollectionOfferStatusExpired, now, models.CollectionOfferStatusOpen)
+	return err
+}
@@ -14,6 +14,7 @@ CREATE TABLE IF NOT EXISTS wallets (
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     address TEXT NOT NULL UNIQUE,
     type TEXT NOT NULL,
+    script_type TEXT NOT NULL DEFAULT '',
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
@@ -49,6 +50,20 @@ CREATE TABLE IF NOT EXISTS email_verifications (
 -- Create index on email_id for faster lookups
 CREATE INDEX IF NOT EXISTS email_verifications_email_id_idx ON email_verifications(email_id);
 
+-- Wallet login challenges table
+CREATE TABLE IF NOT EXISTS auth_challenges (
+    id UUID PRIMARY KEY,
+    address TEXT NOT NULL,
+    nonce TEXT NOT NULL UNIQUE,
+    message TEXT NOT NULL,
+    issued_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+-- Create index on address for faster lookups
+CREATE INDEX IF NOT EXISTS auth_challenges_address_idx ON auth_challenges(address);
+
 -- NFTs table
 CREATE TABLE IF NOT EXISTS nfts (
     id UUID PRIMARY KEY,
@@ -63,7 +78,11 @@ CREATE TABLE IF NOT EXISTS nfts (
     metadata JSONB,
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    auction_id UUID
+    auction_id UUID,
+    location TEXT NOT NULL DEFAULT '',
+    content_type TEXT NOT NULL DEFAULT '',
+    genesis_height BIGINT NOT NULL DEFAULT 0,
+    sat_number BIGINT
 );
 
 -- Create indexes for faster lookups
@@ -76,6 +95,7 @@ CREATE INDEX IF NOT EXISTS nfts_auction_id_idx ON nfts(auction_id);
 -- Auctions table
 CREATE TABLE IF NOT EXISTS auctions (
     id UUID PRIMARY KEY,
+    type TEXT NOT NULL DEFAULT 'english',
     nft_id UUID NOT NULL REFERENCES nfts(id) ON DELETE CASCADE,
     seller_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
     start_price BIGINT NOT NULL,
@@ -87,6 +107,22 @@ CREATE TABLE IF NOT EXISTS auctions (
     end_time TIMESTAMPTZ NOT NULL,
     status TEXT NOT NULL,
     psbt TEXT NOT NULL,
+    settlement_psbt TEXT,
+    settlement_txid TEXT,
+    settlement_wallet_id UUID REFERENCES wallets(id) ON DELETE SET NULL,
+    extension_window INTEGER NOT NULL DEFAULT 0,
+    extension_length INTEGER NOT NULL DEFAULT 0,
+    max_extensions INTEGER,
+    extension_count INTEGER NOT NULL DEFAULT 0,
+    bid_increment JSONB,
+    proxy_max_bid BIGINT,
+    proxy_wallet_id UUID REFERENCES wallets(id) ON DELETE SET NULL,
+    floor_price BIGINT,
+    price_schedule TEXT NOT NULL DEFAULT '',
+    price_step_interval INTEGER NOT NULL DEFAULT 0,
+    reveal_end_time TIMESTAMPTZ,
+    sealed_pricing TEXT NOT NULL DEFAULT '',
+    cancellation_penalty BIGINT,
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
@@ -102,6 +138,17 @@ ALTER TABLE nfts
 ADD CONSTRAINT nfts_auction_id_fkey
 FOREIGN KEY (auction_id) REFERENCES auctions(id) ON DELETE SET NULL;
 
+-- Lot auctions table, listing every NFT sold by a lot auction in order
+CREATE TABLE IF NOT EXISTS auction_lots (
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    nft_id UUID NOT NULL REFERENCES nfts(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (auction_id, nft_id)
+);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS auction_lots_nft_id_idx ON auction_lots(nft_id);
+
 -- Bids table
 CREATE TABLE IF NOT EXISTS bids (
     id UUID PRIMARY KEY,
@@ -111,15 +158,104 @@ CREATE TABLE IF NOT EXISTS bids (
     amount BIGINT NOT NULL,
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     accepted BOOLEAN NOT NULL DEFAULT FALSE,
-    signature TEXT
+    signature TEXT,
+    max_amount BIGINT,
+    automatic BOOLEAN NOT NULL DEFAULT FALSE,
+    message TEXT
 );
 
 -- Create indexes for faster lookups
 CREATE INDEX IF NOT EXISTS bids_auction_id_idx ON bids(auction_id);
+-- A signed bid message can only be used once
+CREATE UNIQUE INDEX IF NOT EXISTS bids_message_idx ON bids(message) WHERE message IS NOT NULL;
 CREATE INDEX IF NOT EXISTS bids_bidder_id_idx ON bids(bidder_id);
 CREATE INDEX IF NOT EXISTS bids_wallet_id_idx ON bids(wallet_id);
 CREATE INDEX IF NOT EXISTS bids_amount_idx ON bids(amount);
 
+-- Sealed bid commitments table
+CREATE TABLE IF NOT EXISTS bid_commitments (
+    id UUID PRIMARY KEY,
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    bidder_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    commitment TEXT NOT NULL,
+    amount BIGINT,
+    status TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revealed_at TIMESTAMPTZ,
+    UNIQUE (auction_id, bidder_id)
+);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS bid_commitments_auction_id_idx ON bid_commitments(auction_id);
+
+-- Auction events table, the append-only log of every change to an auction
+CREATE TABLE IF NOT EXISTS auction_events (
+    id UUID PRIMARY KEY,
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    hidden JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (auction_id, sequence)
+);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS auction_events_type_idx ON auction_events(type);
+
+-- Collection offers table
+CREATE TABLE IF NOT EXISTS collection_offers (
+    id UUID PRIMARY KEY,
+    collection TEXT NOT NULL,
+    offerer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    receive_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    price BIGINT NOT NULL,
+    quantity INTEGER NOT NULL,
+    remaining INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS collection_offers_collection_idx ON collection_offers(collection);
+CREATE INDEX IF NOT EXISTS collection_offers_offerer_id_idx ON collection_offers(offerer_id);
+CREATE INDEX IF NOT EXISTS collection_offers_status_idx ON collection_offers(status);
+
+-- Offers table
+CREATE TABLE IF NOT EXISTS offers (
+    id UUID PRIMARY KEY,
+    nft_id UUID NOT NULL REFERENCES nfts(id) ON DELETE CASCADE,
+    offerer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    receive_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    owner_wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    collection_offer_id UUID REFERENCES collection_offers(id) ON DELETE SET NULL,
+    amount BIGINT NOT NULL,
+    location TEXT NOT NULL,
+    psbt TEXT NOT NULL,
+    settlement_txid TEXT,
+    status TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for faster lookups
+CREATE INDEX IF NOT EXISTS offers_nft_id_idx ON offers(nft_id);
+CREATE INDEX IF NOT EXISTS offers_offerer_id_idx ON offers(offerer_id);
+CREATE INDEX IF NOT EXISTS offers_owner_wallet_id_idx ON offers(owner_wallet_id);
+CREATE INDEX IF NOT EXISTS offers_status_idx ON offers(status);
+CREATE INDEX IF NOT EXISTS offers_collection_offer_id_idx ON offers(collection_offer_id);
+-- An NFT has at most one live fill per collection offer
+CREATE UNIQUE INDEX IF NOT EXISTS offers_collection_offer_nft_idx ON offers(collection_offer_id, nft_id)
+    WHERE collection_offer_id IS NOT NULL AND status IN ('pending', 'open', 'accepted', 'settling');
+
 -- Function to update updated_at timestamp
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
@@ -153,4 +289,31 @@ EXECUTE FUNCTION update_updated_at_column();
 CREATE TRIGGER update_auctions_updated_at
 BEFORE UPDATE ON auctions
 FOR EACH ROW
-EXECUTE FUNCTION update_updated_at_column(); 
\ No newline at end of file
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_offers_updated_at
+BEFORE UPDATE ON offers
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_collection_offers_updated_at
+BEFORE UPDATE ON collection_offers
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Function to keep the auction event log append-only. Events are only
+-- deleted along with their auction.
+CREATE OR REPLACE FUNCTION reject_auction_event_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND NOT EXISTS (SELECT 1 FROM auctions WHERE id = OLD.auction_id) THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'auction events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reject_auction_events_change
+BEFORE UPDATE OR DELETE ON auction_events
+FOR EACH ROW
+EXECUTE FUNCTION reject_auction_event_change();
@@ -107,7 +107,7 @@ func (r *UserRepository) Create() (*models.User, error) {
 // GetWalletsByUserID retrieves wallets for a user
 func (r *UserRepository) GetWalletsByUserID(userID string) ([]models.Wallet, error) {
 	wallets := []models.Wallet{}
-	query := `SELECT id, user_id, address, type, created_at, updated_at 
+	query := `SELECT id, user_id, address, type, script_type, created_at, updated_at 
 			  FROM wallets 
 			  WHERE user_id = $1`
 
@@ -119,10 +119,28 @@ func (r *UserRepository) GetWalletsByUserID(userID string) ([]models.Wallet, err
 	return wallets, nil
 }
 
+// GetWalletByID retrieves a wallet by ID
+func (r *UserRepository) GetWalletByID(id string) (*models.Wallet, error) {
+	wallet := &models.Wallet{}
+	query := `SELECT id, user_id, address, type, script_type, created_at, updated_at 
+			  FROM wallets 
+			  WHERE id = $1`
+
+	err := r.db.GetDB().Get(wallet, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
 // GetWalletByAddress retrieves a wallet by address
 func (r *UserRepository) GetWalletByAddress(address string) (*models.Wallet, error) {
 	wallet := &models.Wallet{}
-	query := `SELECT id, user_id, address, type, created_at, updated_at 
+	query := `SELECT id, user_id, address, type, script_type, created_at, updated_at 
 			  FROM wallets 
 			  WHERE address = $1`
 
@@ -138,7 +156,7 @@ func (r *UserRepository) GetWalletByAddress(address string) (*models.Wallet, err
 }
 
 // AddWallet adds a wallet to a user
-func (r *UserRepository) AddWallet(userID, address, walletType string) (*models.Wallet, error) {
+func (r *UserRepository) AddWallet(userID, address, walletType string, scriptType models.AddressType) (*models.Wallet, error) {
 	// Check if wallet already exists
 	existingWallet, err := r.GetWalletByAddress(address)
 	if err != nil {
@@ -156,18 +174,19 @@ func (r *UserRepository) AddWallet(userID, address, walletType string) (*models.
 	now := time.Now()
 
 	wallet := &models.Wallet{
-		ID:        id,
-		UserID:    userID,
-		Address:   address,
-		Type:      walletType,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:         id,
+		UserID:     userID,
+		Address:    address,
+		Type:       walletType,
+		ScriptType: scriptType,
+		CreatedAt:  now,
+		UpdatedAt:  now,
 	}
 
-	query := `INSERT INTO wallets (id, user_id, address, type, created_at, updated_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6)`
+	query := `INSERT INTO wallets (id, user_id, address, type, script_type, created_at, updated_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
 	_, err = r.db.GetDB().Exec(query, wallet.ID, wallet.UserID, wallet.Address, wallet.Type,
-		wallet.CreatedAt, wallet.UpdatedAt)
+		wallet.ScriptType, wallet.CreatedAt, wallet.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -314,3 +333,55 @@ func (r *UserRepository) GetVerificationCode(emailID string) (*models.EmailVerif
 
 	return verification, nil
 }
+
+// CreateChallenge stores a wallet login challenge
+func (r *UserRepository) CreateChallenge(challenge *models.AuthChallenge) error {
+	if challenge.ID == "" {
+		challenge.ID = uuid.New().String()
+	}
+
+	query := `INSERT INTO auth_challenges (id, address, nonce, message, issued_at, expires_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.GetDB().Exec(query, challenge.ID, challenge.Address, challenge.Nonce,
+		challenge.Message, challenge.IssuedAt, challenge.ExpiresAt)
+
+	return err
+}
+
+// GetChallenge retrieves a wallet login challenge by address and message
+func (r *UserRepository) GetChallenge(address, message string) (*models.AuthChallenge, error) {
+	challenge := &models.AuthChallenge{}
+	query := `SELECT id, address, nonce, message, issued_at, expires_at, used_at 
+			  FROM auth_challenges 
+			  WHERE address = $1 AND message = $2`
+
+	err := r.db.GetDB().Get(challenge, query, address, message)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// ConsumeChallenge marks a challenge as used. It returns false if the challenge
+// was already used or has expired, so a challenge can only be consumed once.
+func (r *UserRepository) ConsumeChallenge(id string) (bool, error) {
+	now := time.Now()
+	query := `UPDATE auth_challenges SET used_at = $1 
+			  WHERE id = $2 AND used_at IS NULL AND expires_at > $1`
+
+	result, err := r.db.GetDB().Exec(query, now, id)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}